@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a subcommand of application. Without subcommand cast playback is performed.
+type command struct {
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]command{
+	"convert":  {description: "convert recording between formats", run: convertCommand},
+	"cut":      {description: "keep only given time range of recording", run: cutCommand},
+	"trim":     {description: "remove time from beginning and end of recording", run: trimCommand},
+	"splice":   {description: "remove time range from recording", run: spliceCommand},
+	"cat":      {description: "concatenate recordings", run: catCommand},
+	"export":   {description: "export recording as text, transcript or HTML page", run: exportCommand},
+	"info":     {description: "show recording statistics", run: infoCommand},
+	"search":   {description: "find text that appeared on screen", run: searchCommand},
+	"ssh":      {description: "serve recordings over SSH", run: sshCommand},
+	"telnet":   {description: "serve recordings over telnet or raw TCP", run: telnetCommand},
+	"filter":   {description: "keep only frames of given types", run: filterCommand},
+	"redact":   {description: "redact secrets in recording", run: redactCommand},
+	"validate": {description: "check recording for errors and suspicious content", run: validateCommand},
+	"retime":   {description: "change speed, limit pauses and normalize typing of recording", run: retimeCommand},
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(out, "  %s [flags]\tplay recording\n", os.Args[0])
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s %s [flags]\t%s\n", os.Args[0], name, commands[name].description)
+	}
+
+	fmt.Fprintln(out, "Flags:")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const (
+	formatAsciicast = "asciicast"
+	formatTTYRec    = "ttyrec"
+	formatScript    = "script"
+)
+
+var formatByExtension = map[string]string{
+	".cast":   formatAsciicast,
+	".ttyrec": formatTTYRec,
+	".tty":    formatTTYRec,
+}
+
+// detectFormat returns format by file extension if it's not specified explicitly. Fallback used for unknown extensions.
+func detectFormat(format, path, fallback string) (string, error) {
+	if format == "" {
+		format = formatByExtension[strings.ToLower(filepath.Ext(path))]
+	}
+
+	if format == "" {
+		format = fallback
+	}
+
+	switch format {
+	case formatAsciicast, formatTTYRec, formatScript:
+		return format, nil
+	case "":
+		return "", fmt.Errorf("can't detect format of %q, please specify it", path)
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func convertCommand(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := flags.String("from", "", "input format: asciicast, ttyrec, script (detected by extension if empty)")
+	to := flags.String("to", "", "output format: asciicast, ttyrec (detected by extension if empty, asciicast by default)")
+	input := flags.String("i", "", "input file path (typescript for script format), \"-\" for stdin")
+	timing := flags.String("timing", "", "timing log for script format, format is script if set")
+	inputLog := flags.String("input-log", "", "input log for script format if it was recorded to separate file (\"--log-in\")")
+	output := flags.String("o", "-", "output file path, \"-\" for stdout")
+	width := flags.Int("width", 0, "terminal width for formats that don't store it, inferred if zero")
+	height := flags.Int("height", 0, "terminal height for formats that don't store it, inferred if zero")
+	flags.BoolVar(&lenient, "lenient", false, lenientUsage)
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	if *from == "" && *timing != "" {
+		*from = formatScript
+	}
+
+	fromFormat, err := detectFormat(*from, *input, "")
+	if err != nil {
+		return err
+	}
+
+	toFormat, err := detectFormat(*to, *output, formatAsciicast)
+	if err != nil {
+		return err
+	}
+
+	if toFormat == formatScript {
+		return fmt.Errorf("conversion to script format is not supported")
+	}
+
+	in, err := openInput(*input)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	var src player.FrameSource
+
+	switch fromFormat {
+	case formatAsciicast:
+		src, err = player.NewStreamFrameSource(in, streamOptions()...)
+	case formatTTYRec:
+		src, err = newTTYRecSource(in, *width, *height)
+	case formatScript:
+		src, err = newScriptSource(in, *timing, *inputLog, *width, *height)
+	}
+
+	if err != nil {
+		return fmt.Errorf("read input failed: %w", err)
+	}
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	var dst player.FrameWriter
+
+	switch toFormat {
+	case formatAsciicast:
+		dst, err = player.NewStreamFrameWriter(out, src.Header())
+		if err != nil {
+			return err
+		}
+	case formatTTYRec:
+		dst = player.NewTTYRecWriter(out, src.Header())
+	}
+
+	if err = player.CopyFrames(dst, src); err != nil {
+		return fmt.Errorf("convert failed: %w", err)
+	}
+
+	reportSkipped(*input, src)
+
+	return out.Close()
+}
+
+// newTTYRecSource constructs ttyrec frame source. If dimensions are not provided they are inferred.
+func newTTYRecSource(in io.Reader, width, height int) (player.FrameSource, error) {
+	if width > 0 && height > 0 {
+		return player.NewTTYRecFrameSource(in, width, height)
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	return withInferredDimensions(width, height, func(width, height int) (player.FrameSource, error) {
+		return player.NewTTYRecFrameSource(bytes.NewReader(data), width, height)
+	})
+}
+
+// newScriptSource constructs script frame source. If dimensions are not provided and not stored in recording they are inferred.
+func newScriptSource(typescript io.Reader, timingPath, inputLogPath string, width, height int) (player.FrameSource, error) {
+	if timingPath == "" {
+		return nil, fmt.Errorf("timing log not specified")
+	}
+
+	timing, err := os.ReadFile(timingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := io.ReadAll(typescript)
+	if err != nil {
+		return nil, err
+	}
+
+	var input []byte
+	if inputLogPath != "" {
+		if input, err = os.ReadFile(inputLogPath); err != nil {
+			return nil, err
+		}
+	}
+
+	newSource := func(width, height int) (player.FrameSource, error) {
+		var inputReader io.Reader
+		if input != nil {
+			inputReader = bytes.NewReader(input)
+		}
+
+		return player.NewScriptFrameSource(bytes.NewReader(timing), bytes.NewReader(output), inputReader, width, height)
+	}
+
+	src, err := newSource(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr := src.Header(); hdr.Width > 0 && hdr.Height > 0 {
+		return src, nil
+	}
+
+	return withInferredDimensions(width, height, newSource)
+}
+
+// withInferredDimensions constructs frame source with missing dimensions inferred using first pass over source.
+func withInferredDimensions(width, height int, newSource func(width, height int) (player.FrameSource, error)) (player.FrameSource, error) {
+	src, err := newSource(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	inferredWidth, inferredHeight, err := player.InferDimensions(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if hdr := src.Header(); width <= 0 {
+		width = hdr.Width
+		if width <= 0 {
+			width = inferredWidth
+		}
+	}
+
+	if hdr := src.Header(); height <= 0 {
+		height = hdr.Height
+		if height <= 0 {
+			height = inferredHeight
+		}
+	}
+
+	return newSource(width, height)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// writeCast writes frames from source to output path in asciicast v2 format.
+func writeCast(output string, src player.FrameSource) error {
+	out, err := createOutput(output)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	writer, err := player.NewStreamFrameWriter(out, src.Header())
+	if err != nil {
+		return err
+	}
+
+	if err = player.CopyFrames(writer, src); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// editCast applies transformation to input cast and writes result to output.
+func editCast(input, output string, edit func(player.FrameSource) player.FrameSource) error {
+	src, closer, err := openCast(input)
+	if err != nil {
+		return err
+	}
+
+	defer closer.Close()
+
+	if err = writeCast(output, edit(src)); err != nil {
+		return err
+	}
+
+	reportSkipped(input, src)
+
+	return nil
+}
+
+// newEditFlags creates flag set with common input and output flags.
+func newEditFlags(name string) (flags *flag.FlagSet, input, output *string) {
+	flags = flag.NewFlagSet(name, flag.ExitOnError)
+	input = flags.String("i", "", "input asciicast v2 file path, \"-\" for stdin")
+	output = flags.String("o", "-", "output file path, \"-\" for stdout")
+	flags.BoolVar(&lenient, "lenient", false, lenientUsage)
+
+	return flags, input, output
+}
+
+func cutCommand(args []string) error {
+	flags, input, output := newEditFlags("cut")
+	from := flags.Duration("from", 0, "beginning of kept part")
+	to := flags.Duration("to", 0, "end of kept part, zero means end of recording")
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	if *to > 0 && *to <= *from {
+		return fmt.Errorf("end of kept part must be after beginning")
+	}
+
+	return editCast(*input, *output, func(src player.FrameSource) player.FrameSource {
+		return player.Cut(src, from.Seconds(), to.Seconds())
+	})
+}
+
+func trimCommand(args []string) error {
+	flags, input, output := newEditFlags("trim")
+	start := flags.Duration("start", 0, "time to remove from beginning")
+	end := flags.Duration("end", 0, "time to remove from end")
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	return editCast(*input, *output, func(src player.FrameSource) player.FrameSource {
+		return player.Trim(src, start.Seconds(), end.Seconds())
+	})
+}
+
+func spliceCommand(args []string) error {
+	flags, input, output := newEditFlags("splice")
+	from := flags.Duration("from", 0, "beginning of removed part")
+	to := flags.Duration("to", 0, "end of removed part")
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	if *to <= *from {
+		return fmt.Errorf("end of removed part must be after beginning")
+	}
+
+	return editCast(*input, *output, func(src player.FrameSource) player.FrameSource {
+		return player.Splice(src, from.Seconds(), to.Seconds())
+	})
+}
+
+func catCommand(args []string) error {
+	flags := flag.NewFlagSet("cat", flag.ExitOnError)
+	output := flags.String("o", "-", "output file path, \"-\" for stdout")
+	gap := flags.Duration("gap", time.Second, "pause between recordings")
+	flags.BoolVar(&lenient, "lenient", false, lenientUsage)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cat [flags] input1.cast input2.cast ...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("input files not specified")
+	}
+
+	sources := make([]player.FrameSource, 0, flags.NArg())
+	for _, input := range flags.Args() {
+		src, closer, err := openCast(input)
+		if err != nil {
+			return err
+		}
+
+		defer closer.Close()
+
+		sources = append(sources, src)
+	}
+
+	if err := writeCast(*output, player.Concat(gap.Seconds(), sources...)); err != nil {
+		return err
+	}
+
+	for i, input := range flags.Args() {
+		reportSkipped(input, sources[i])
+	}
+
+	return nil
+}
+
+func filterCommand(args []string) error {
+	flags, input, output := newEditFlags("filter")
+	types := flags.String("types", "o,r,m", "comma-separated frame types to keep: o - output, i - input, r - resize, m - marker")
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	keep := map[player.FrameType]bool{}
+	for _, frameType := range strings.Split(*types, ",") {
+		switch frameType := player.FrameType(strings.TrimSpace(frameType)); frameType {
+		case player.OutputFrame, player.InputFrame, player.ResizeFrame, player.MarkerFrame:
+			keep[frameType] = true
+		default:
+			return fmt.Errorf("unknown frame type %q", frameType)
+		}
+	}
+
+	return editCast(*input, *output, func(src player.FrameSource) player.FrameSource {
+		return player.Filter(src, func(frame player.Frame) bool { return keep[frame.Type] })
+	})
+}
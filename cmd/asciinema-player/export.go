@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// exporters are supported export formats.
+var exporters = map[string]struct {
+	description string
+	export      func(w io.Writer, src player.FrameSource, opts ...player.Option) error
+}{
+	"text": {
+		description: "scrollback and final screen as plain text",
+		export: func(w io.Writer, src player.FrameSource, _ ...player.Option) error {
+			return player.ExportText(w, src)
+		},
+	},
+	"transcript": {
+		description: "every line once with time when it appeared",
+		export: func(w io.Writer, src player.FrameSource, _ ...player.Option) error {
+			return player.ExportTranscript(w, src)
+		},
+	},
+	"html": {
+		description: "self-contained HTML page with built-in player, playback flags are applied",
+		export:      player.ExportHTML,
+	},
+}
+
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "-", "output file path, \"-\" for stdout")
+	maxWait := flags.Duration("maxWait", 2*time.Second, "maximum time between frames")
+	speed := flags.Float64("speed", 1, "speed adjustment: >1 - faster, <1 - slower")
+	startAt := flags.Duration("start", 0, "start playback from given position")
+	flags.BoolVar(&lenient, "lenient", false, lenientUsage)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: export format [flags] input.cast")
+		fmt.Fprintln(flags.Output(), "Formats:")
+
+		formats := make([]string, 0, len(exporters))
+		for format := range exporters {
+			formats = append(formats, format)
+		}
+
+		sort.Strings(formats)
+
+		for _, format := range formats {
+			fmt.Fprintf(flags.Output(), "  %s\t%s\n", format, exporters[format].description)
+		}
+
+		fmt.Fprintln(flags.Output(), "Flags:")
+		flags.PrintDefaults()
+	}
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		flags.Usage()
+		return fmt.Errorf("export format must be specified")
+	}
+
+	exporter, ok := exporters[args[0]]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown export format %q", args[0])
+	}
+
+	flags.Parse(args[1:])
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("single input file must be specified")
+	}
+
+	input := flags.Arg(0)
+
+	src, closer, err := openCast(input)
+	if err != nil {
+		return err
+	}
+
+	defer closer.Close()
+
+	out, err := createOutput(*output)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	opts := []player.Option{player.WithMaxWait(*maxWait), player.WithSpeed(*speed), player.WithStartAt(startAt.Seconds())}
+
+	if err = exporter.export(out, src, opts...); err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+
+	reportSkipped(input, src)
+
+	return out.Close()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// openInput opens cast location for reading, see castOpener for supported locations.
+func openInput(location string) (io.ReadCloser, error) {
+	opener := castOpener{ServerURL: serverURLFromEnv()}
+
+	return opener.Open(location)
+}
+
+// openCast opens asciicast v2 file.
+func openCast(location string) (*player.StreamFrameSource, io.Closer, error) {
+	file, err := openInput(location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src, err := player.NewStreamFrameSource(file, streamOptions()...)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("%s: %w", location, err)
+	}
+
+	return src, file, nil
+}
+
+// streamOptions returns options for reading asciicast v2 files according to flags.
+func streamOptions() []player.StreamOption {
+	if lenient {
+		return []player.StreamOption{player.WithLenientParsing()}
+	}
+
+	return nil
+}
+
+// reportSkipped prints lines skipped in lenient mode to stderr.
+func reportSkipped(location string, src player.FrameSource) {
+	stream, ok := src.(*player.StreamFrameSource)
+	if !ok {
+		return
+	}
+
+	for _, skipped := range stream.Skipped() {
+		fmt.Fprintf(os.Stderr, "%s: skipped %s\n", location, skipped)
+	}
+}
+
+// bufferedOutput is a buffered output file.
+type bufferedOutput struct {
+	*bufio.Writer
+	file *os.File
+}
+
+// createOutput creates output file, "-" means stdout.
+func createOutput(path string) (*bufferedOutput, error) {
+	file := os.Stdout
+	if path != stdinLocation {
+		var err error
+
+		file, err = os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &bufferedOutput{Writer: bufio.NewWriter(file), file: file}, nil
+}
+
+// Close flushes buffer and closes file. Stdout is not closed.
+func (o *bufferedOutput) Close() error {
+	if err := o.Flush(); err != nil {
+		return err
+	}
+
+	if o.file == os.Stdout {
+		return nil
+	}
+
+	return o.file.Close()
+}
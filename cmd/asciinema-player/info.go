@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// frameTypeNames are human-readable names of frame types in order of output.
+var frameTypeNames = []struct {
+	frameType player.FrameType
+	name      string
+}{
+	{player.OutputFrame, "output"},
+	{player.InputFrame, "input"},
+	{player.ResizeFrame, "resize"},
+	{player.MarkerFrame, "marker"},
+}
+
+func infoCommand(args []string) error {
+	flags := flag.NewFlagSet("info", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "print information as JSON")
+	idleLimit := flags.Duration("idle-limit", 2*time.Second, "pause limit for compressed duration")
+	flags.BoolVar(&lenient, "lenient", false, lenientUsage)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: info [flags] input.cast")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("single input file must be specified")
+	}
+
+	input := flags.Arg(0)
+
+	src, closer, err := openCast(input)
+	if err != nil {
+		return err
+	}
+
+	defer closer.Close()
+
+	stats, err := player.CollectStats(src, idleLimit.Seconds())
+	if err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+
+	reportSkipped(input, src)
+
+	if *jsonOutput {
+		return writeStatsJSON(os.Stdout, stats)
+	}
+
+	writeStatsText(os.Stdout, input, stats, *idleLimit)
+
+	return nil
+}
+
+func writeStatsText(w io.Writer, input string, stats *player.Stats, idleLimit time.Duration) {
+	hdr := stats.Header
+
+	fmt.Fprintf(w, "File:        %s\n", input)
+	fmt.Fprintf(w, "Version:     %d\n", hdr.Version)
+	fmt.Fprintf(w, "Size:        %dx%d\n", hdr.Width, hdr.Height)
+
+	if hdr.Timestamp != 0 {
+		fmt.Fprintf(w, "Recorded:    %s\n", time.Unix(hdr.Timestamp, 0).Format(time.RFC3339))
+	}
+
+	fmt.Fprintf(w, "Duration:    %s (%s with pauses limited to %s)\n",
+		seconds(stats.Duration), seconds(stats.CompressedDuration), idleLimit)
+
+	var frames, sizes []string
+	for _, t := range frameTypeNames {
+		frames = append(frames, fmt.Sprintf("%s %d", t.name, stats.Frames[t.frameType]))
+		sizes = append(sizes, fmt.Sprintf("%s %d", t.name, stats.Bytes[t.frameType]))
+	}
+
+	fmt.Fprintf(w, "Frames:      %s\n", strings.Join(frames, ", "))
+	fmt.Fprintf(w, "Bytes:       %s\n", strings.Join(sizes, ", "))
+
+	if len(stats.Features) > 0 {
+		fmt.Fprintf(w, "Features:    %s\n", strings.Join(stats.Features, ", "))
+	}
+
+	if len(stats.Pauses) > 0 {
+		fmt.Fprintln(w, "Longest pauses:")
+		for _, p := range stats.Pauses {
+			fmt.Fprintf(w, "  %s at %s\n", seconds(p.Duration), seconds(p.Time))
+		}
+	}
+
+	if len(stats.Markers) > 0 {
+		fmt.Fprintln(w, "Markers:")
+		for _, m := range stats.Markers {
+			fmt.Fprintf(w, "  %s %s\n", seconds(m.Time), m.Label)
+		}
+	}
+
+	if len(stats.Resizes) > 0 {
+		fmt.Fprintln(w, "Resizes:")
+		for _, r := range stats.Resizes {
+			fmt.Fprintf(w, "  %s %dx%d\n", seconds(r.Time), r.Width, r.Height)
+		}
+	}
+}
+
+// seconds formats time in seconds as duration rounded to milliseconds.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
+
+func writeStatsJSON(w io.Writer, stats *player.Stats) error {
+	type pause struct {
+		Time     float64 `json:"time"`
+		Duration float64 `json:"duration"`
+	}
+
+	type marker struct {
+		Time  float64 `json:"time"`
+		Label string  `json:"label"`
+	}
+
+	type resize struct {
+		Time   float64 `json:"time"`
+		Width  int     `json:"width"`
+		Height int     `json:"height"`
+	}
+
+	report := struct {
+		Header             player.Header  `json:"header"`
+		Duration           float64        `json:"duration"`
+		CompressedDuration float64        `json:"compressed_duration"`
+		Frames             map[string]int `json:"frames"`
+		Bytes              map[string]int `json:"bytes"`
+		Pauses             []pause        `json:"pauses"`
+		Markers            []marker       `json:"markers"`
+		Resizes            []resize       `json:"resizes"`
+		Features           []string       `json:"features"`
+	}{
+		Header:             stats.Header,
+		Duration:           stats.Duration,
+		CompressedDuration: stats.CompressedDuration,
+		Frames:             map[string]int{},
+		Bytes:              map[string]int{},
+		Pauses:             []pause{},
+		Markers:            []marker{},
+		Resizes:            []resize{},
+		Features:           stats.Features,
+	}
+
+	for _, t := range frameTypeNames {
+		report.Frames[t.name] = stats.Frames[t.frameType]
+		report.Bytes[t.name] = stats.Bytes[t.frameType]
+	}
+
+	for _, p := range stats.Pauses {
+		report.Pauses = append(report.Pauses, pause(p))
+	}
+
+	for _, m := range stats.Markers {
+		report.Markers = append(report.Markers, marker(m))
+	}
+
+	for _, r := range stats.Resizes {
+		report.Resizes = append(report.Resizes, resize(r))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(report)
+}
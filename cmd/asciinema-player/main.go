@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"time"
 
@@ -17,37 +19,145 @@ func errExit(err error) {
 }
 
 var (
-	maxWait  time.Duration
-	speed    float64
-	filePath string
+	maxWait   time.Duration
+	speed     float64
+	filePath  string
+	serverURL string
+	cacheDir  string
+	follow    bool
+	lenient   bool
+	startAt   time.Duration
+	teePath   string
 )
 
+const lenientUsage = "skip malformed frames and truncated last line instead of failing"
+
 func init() {
 	flag.DurationVar(&maxWait, "maxWait", 2*time.Second, "maximum time between frames")
 	flag.Float64Var(&speed, "speed", 1, "speed adjustment: <1 - increase, >1 - decrease")
-	flag.StringVar(&filePath, "f", "", "path to asciinema v2 file")
-	flag.Parse()
+	flag.StringVar(&filePath, "f", "", "path, http(s) URL, asciinema server recording URL or ID (with \"id:\" prefix) of asciinema v2 file, \"-\" for stdin")
+	flag.StringVar(&serverURL, "server", serverURLFromEnv(), "asciinema server base URL used to resolve recording IDs")
+	flag.StringVar(&cacheDir, "cache-dir", "", "directory to cache downloaded casts, caching disabled if empty")
+	flag.BoolVar(&follow, "follow", false, "wait for new frames after end of file like \"tail -f\"")
+	flag.BoolVar(&lenient, "lenient", false, lenientUsage)
+	flag.DurationVar(&startAt, "start", 0, "start playback from given position (i.e. time of search hit)")
+	flag.StringVar(&teePath, "tee", "", "also write played output to file (i.e. log or named pipe)")
+
+	flag.Usage = usage
+}
+
+func serverURLFromEnv() string {
+	if u := os.Getenv("ASCIINEMA_API_URL"); u != "" {
+		return u
+	}
+
+	return DefaultServerURL
+}
+
+// closingFrameSource attaches io.Closer to FrameSource, so Player.Stop closes it.
+type closingFrameSource struct {
+	player.FrameSource
+	io.Closer
 }
 
+// newTerminal constructs terminal for playback. If stdin is occupied by cast, controlling terminal used.
+// Returned function closes opened controlling terminal, it must be called after terminal is closed.
+func newTerminal(castFromStdin bool) (*player.OSTerminal, func(), error) {
+	if !castFromStdin {
+		term, err := player.NewOSTerminal()
+		return term, func() {}, err
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open controlling terminal failed: %w", err)
+	}
+
+	term, err := player.NewOSTerminalFromFile(tty)
+	if err != nil {
+		tty.Close()
+		return nil, nil, err
+	}
+
+	return term, func() { tty.Close() }, nil
+}
+
+// writerTerminal is a secondary terminal writing output to file, it doesn't limit terminal size.
+type writerTerminal struct {
+	io.WriteCloser
+}
+
+func (w writerTerminal) Dimensions() (width, height int) { return math.MaxInt32, math.MaxInt32 }
+
+func (w writerTerminal) ToRaw() error { return nil }
+
+func (w writerTerminal) Restore() error { return nil }
+
+func (w writerTerminal) Control(player.PlaybackControl) {}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			errExit(cmd.run(os.Args[2:]))
+			return
+		}
+	}
+
+	flag.Parse()
+
 	if filePath == "" {
 		fmt.Println("Please specify file\nUsage:")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	file, err := os.Open(filePath)
+	opener := castOpener{ServerURL: serverURL, CacheDir: cacheDir}
+
+	file, err := opener.Open(filePath)
 	errExit(err)
 	defer file.Close()
 
-	source, err := player.NewStreamFrameSource(file)
+	var (
+		reader io.Reader = file
+		source player.FrameSource
+	)
+
+	if follow {
+		followReader := player.NewFollowReader(file, player.DefaultFollowInterval)
+		reader = followReader
+		defer followReader.Close()
+	}
+
+	streamSource, err := player.NewStreamFrameSource(reader, streamOptions()...)
 	errExit(err)
 
-	term, err := player.NewOSTerminal()
+	defer reportSkipped(filePath, streamSource) // after terminal restored
+
+	source = streamSource
+	if closer, ok := reader.(io.Closer); ok {
+		source = &closingFrameSource{FrameSource: streamSource, Closer: closer} // allow to interrupt waiting for frames
+	}
+
+	osTerm, closeTTY, err := newTerminal(filePath == stdinLocation)
 	errExit(err)
+	defer closeTTY() // after terminal closed
+
+	var term player.Terminal = osTerm
+
+	if teePath != "" {
+		teeFile, err := os.Create(teePath)
+		errExit(err)
+
+		tee := player.NewTeeTerminal(osTerm, writerTerminal{teeFile})
+		defer reportTeeErrors(tee) // after terminal restored
+
+		term = tee
+	}
+
 	defer term.Close()
 
-	p, err := player.NewPlayer(source, term, player.WithSpeed(speed), player.WithMaxWait(maxWait), player.WithIgnoreSizeCheck())
+	p, err := player.NewPlayer(source, term,
+		player.WithSpeed(speed), player.WithMaxWait(maxWait), player.WithStartAt(startAt.Seconds()), player.WithIgnoreSizeCheck())
 	errExit(err)
 
 	err = p.Start()
@@ -55,3 +165,11 @@ func main() {
 		fmt.Println("Start failed:", err)
 	}
 }
+
+func reportTeeErrors(tee *player.TeeTerminal) {
+	for _, err := range tee.Errors() {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Tee failed:", err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// stringsFlag is a repeatable string flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ", ") }
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func redactCommand(args []string) error {
+	var literals, regexps stringsFlag
+
+	flags, input, output := newEditFlags("redact")
+	flags.Var(&literals, "literal", "literal string to redact (repeatable)")
+	flags.Var(&regexps, "regexp", "regular expression to redact, only group named \"secret\" redacted if present (repeatable)")
+	noBuiltin := flags.Bool("no-builtin", false, "disable built-in detectors (AWS keys, JWTs, PEM private keys, API tokens)")
+	report := flags.String("report", "", "path to write JSON report, summary printed to stderr if empty")
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	var rules []player.RedactRule
+	if !*noBuiltin {
+		rules = player.DefaultRedactRules()
+	}
+
+	for i, literal := range literals {
+		rules = append(rules, player.LiteralRule("literal#"+strconv.Itoa(i+1), literal))
+	}
+
+	for i, expr := range regexps {
+		rule, err := player.RegexpRule("regexp#"+strconv.Itoa(i+1), expr)
+		if err != nil {
+			return err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == 0 {
+		return fmt.Errorf("no rules to apply")
+	}
+
+	var redactor *player.RedactFrameSource
+
+	err := editCast(*input, *output, func(src player.FrameSource) player.FrameSource {
+		redactor = player.NewRedactFrameSource(src, rules...)
+		return redactor
+	})
+	if err != nil {
+		return err
+	}
+
+	if *report != "" {
+		return writeRedactionReport(*report, redactor.Redactions())
+	}
+
+	for _, r := range redactor.Redactions() {
+		fmt.Fprintf(os.Stderr, "%10.3fs %s %s: %d characters\n", r.Time, r.Type, r.Rule, r.Length)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d secrets redacted\n", len(redactor.Redactions()))
+
+	return nil
+}
+
+func writeRedactionReport(path string, redactions []player.Redaction) error {
+	type reportEntry struct {
+		Rule   string           `json:"rule"`
+		Type   player.FrameType `json:"type"`
+		Time   float64          `json:"time"`
+		Length int              `json:"length"`
+	}
+
+	entries := make([]reportEntry, 0, len(redactions))
+	for _, r := range redactions {
+		entries = append(entries, reportEntry(r))
+	}
+
+	out, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	if err = enc.Encode(entries); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultServerURL is a base URL of public asciinema server.
+	DefaultServerURL = "https://asciinema.org"
+
+	stdinLocation = "-"
+
+	// recordingIDPrefix marks location as recording ID, so missing local file isn't fetched from server by mistake.
+	recordingIDPrefix = "id:"
+
+	// Downloads are streamed during playback, so timeouts are set for connection and response header, not for whole request.
+	downloadDialTimeout   = 30 * time.Second
+	downloadHeaderTimeout = 30 * time.Second
+)
+
+var recordingIDRegexp = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// castOpener opens casts by location. Location may be a local path, "-" for stdin, http(s) URL,
+// asciinema server recording URL (i.e. https://asciinema.org/a/189343) or recording ID with "id:" prefix (i.e. id:189343).
+type castOpener struct {
+	// ServerURL is a base URL of asciinema server used to resolve recording IDs.
+	ServerURL string
+
+	// CacheDir is a directory to store downloaded casts. Caching is disabled if empty.
+	CacheDir string
+
+	// Client used for downloads. Client with connection and response header timeouts is used if nil.
+	Client *http.Client
+}
+
+// Open returns stream with cast contents. Remote casts are streamed as is so playback may start before download finishes.
+func (o *castOpener) Open(location string) (io.ReadCloser, error) {
+	if location == stdinLocation {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	castURL, remote, err := o.resolve(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if !remote {
+		return os.Open(location)
+	}
+
+	var cachePath string
+	if o.CacheDir != "" {
+		sum := sha256.Sum256([]byte(castURL))
+		cachePath = filepath.Join(o.CacheDir, hex.EncodeToString(sum[:])+".cast")
+
+		file, err := os.Open(cachePath)
+		switch {
+		case err == nil:
+			return file, nil
+		case os.IsNotExist(err):
+			// download
+		default:
+			return nil, fmt.Errorf("open cached cast failed: %w", err)
+		}
+	}
+
+	client := o.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Get(castURL)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("download failed: unexpected status %s", resp.Status)
+	}
+
+	if cachePath == "" {
+		return resp.Body, nil
+	}
+
+	return newCachingReader(resp.Body, cachePath)
+}
+
+// resolve converts location to URL. It returns false if location points to local file.
+func (o *castOpener) resolve(location string) (string, bool, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", false, fmt.Errorf("parse url failed: %w", err)
+		}
+
+		// recording page url, cast file is available by same path with extension
+		if dir, id := path.Split(u.Path); dir == "/a/" && recordingIDRegexp.MatchString(id) {
+			u.Path += ".cast"
+		}
+
+		return u.String(), true, nil
+	}
+
+	id := strings.TrimPrefix(location, recordingIDPrefix)
+	if id == location {
+		return location, false, nil
+	}
+
+	if !recordingIDRegexp.MatchString(id) {
+		return "", false, fmt.Errorf("invalid recording ID %q", id)
+	}
+
+	serverURL := o.ServerURL
+	if serverURL == "" {
+		serverURL = DefaultServerURL
+	}
+
+	return strings.TrimSuffix(serverURL, "/") + "/a/" + id + ".cast", true, nil
+}
+
+var defaultClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: downloadDialTimeout}).DialContext,
+		TLSHandshakeTimeout:   downloadDialTimeout,
+		ResponseHeaderTimeout: downloadHeaderTimeout,
+	},
+}
+
+// cachingReader writes all read data to temporary file and moves it to cache after full read.
+type cachingReader struct {
+	body      io.ReadCloser
+	tmp       *os.File
+	cachePath string
+	done      bool
+}
+
+func newCachingReader(body io.ReadCloser, cachePath string) (*cachingReader, error) {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+		body.Close()
+		return nil, fmt.Errorf("create cache dir failed: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), filepath.Base(cachePath)+".*.tmp")
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("create cache file failed: %w", err)
+	}
+
+	return &cachingReader{
+		body:      body,
+		tmp:       tmp,
+		cachePath: cachePath,
+	}, nil
+}
+
+func (r *cachingReader) Read(p []byte) (n int, err error) {
+	n, err = r.body.Read(p)
+	if n > 0 {
+		if _, writeErr := r.tmp.Write(p[:n]); writeErr != nil {
+			return n, fmt.Errorf("write cache failed: %w", writeErr)
+		}
+	}
+
+	if err == io.EOF {
+		r.done = true
+	}
+
+	return n, err
+}
+
+// Close closes download stream. Cache file is kept only if whole stream was read.
+func (r *cachingReader) Close() error {
+	bodyErr := r.body.Close()
+
+	if err := r.tmp.Close(); err != nil || !r.done {
+		os.Remove(r.tmp.Name())
+		return bodyErr
+	}
+
+	if err := os.Rename(r.tmp.Name(), r.cachePath); err != nil {
+		os.Remove(r.tmp.Name())
+		return fmt.Errorf("store cache failed: %w", err)
+	}
+
+	return bodyErr
+}
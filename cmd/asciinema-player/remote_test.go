@@ -0,0 +1,150 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func newCastServer(t *testing.T, cast []byte) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path != "/a/189343.cast" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write(cast)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &hits
+}
+
+func readAllAndClose(t *testing.T, rc io.ReadCloser) []byte {
+	t.Helper()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+
+	if err = rc.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	return data
+}
+
+func TestCastOpener_Open(t *testing.T) {
+	cast, err := os.ReadFile(filepath.Join("..", "..", "testdata", "test.cast"))
+	if err != nil {
+		t.Fatalf("Cast read failed: %s", err)
+	}
+
+	srv, hitsCount := newCastServer(t, cast)
+	opener := castOpener{ServerURL: srv.URL}
+
+	for _, location := range []string{
+		srv.URL + "/a/189343.cast",
+		srv.URL + "/a/189343",
+		"id:189343",
+		filepath.Join("..", "..", "testdata", "test.cast"),
+	} {
+		rc, err := opener.Open(location)
+		if err != nil {
+			t.Fatalf("Open %q failed: %s", location, err)
+		}
+
+		if data := readAllAndClose(t, rc); string(data) != string(cast) {
+			t.Fatalf("Open %q returned unexpected content", location)
+		}
+	}
+
+	if _, err = opener.Open(srv.URL + "/a/unknown"); err == nil {
+		t.Fatalf("Open of missing recording must fail")
+	}
+
+	// missing local file looking like ID isn't downloaded
+	hits := atomic.LoadInt32(hitsCount)
+	if _, err = opener.Open("189343"); !os.IsNotExist(err) {
+		t.Fatalf("Open of missing file must fail with not exist error, got %v", err)
+	}
+
+	if atomic.LoadInt32(hitsCount) != hits {
+		t.Fatalf("Missing file was downloaded")
+	}
+}
+
+func TestCastOpener_Cache(t *testing.T) {
+	cast, err := os.ReadFile(filepath.Join("..", "..", "testdata", "test.cast"))
+	if err != nil {
+		t.Fatalf("Cast read failed: %s", err)
+	}
+
+	srv, hits := newCastServer(t, cast)
+	opener := castOpener{ServerURL: srv.URL, CacheDir: t.TempDir()}
+
+	for i := 0; i < 2; i++ {
+		rc, err := opener.Open("id:189343")
+		if err != nil {
+			t.Fatalf("Open failed: %s", err)
+		}
+
+		if data := readAllAndClose(t, rc); string(data) != string(cast) {
+			t.Fatalf("Open returned unexpected content")
+		}
+	}
+
+	if n := atomic.LoadInt32(hits); n != 1 {
+		t.Fatalf("Expected 1 request to server, got %d", n)
+	}
+
+	entries, err := os.ReadDir(opener.CacheDir)
+	if err != nil {
+		t.Fatalf("Read cache dir failed: %s", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 file in cache, got %d", len(entries))
+	}
+}
+
+func TestCastOpener_CachePartialRead(t *testing.T) {
+	cast, err := os.ReadFile(filepath.Join("..", "..", "testdata", "test.cast"))
+	if err != nil {
+		t.Fatalf("Cast read failed: %s", err)
+	}
+
+	srv, _ := newCastServer(t, cast)
+	opener := castOpener{ServerURL: srv.URL, CacheDir: t.TempDir()}
+
+	rc, err := opener.Open("id:189343")
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	if _, err = rc.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("Read failed: %s", err)
+	}
+
+	if err = rc.Close(); err != nil {
+		t.Fatalf("Close failed: %s", err)
+	}
+
+	entries, err := os.ReadDir(opener.CacheDir)
+	if err != nil {
+		t.Fatalf("Read cache dir failed: %s", err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("Partially read cast must not be cached")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func retimeCommand(args []string) error {
+	flags, input, output := newEditFlags("retime")
+	speed := flags.Float64("speed", 1, "speed multiplier: >1 - faster, <1 - slower")
+	idleLimit := flags.Duration("idle-limit", 0, "maximal pause between frames, zero means no limit")
+	typing := flags.Duration("typing", 0, "constant delay between typed characters, zero keeps original typing")
+	hold := flags.Duration("hold", 0, "time to keep last screen at the end")
+	coalesce := flags.Duration("coalesce", 0, "merge adjacent output or input frames within this time")
+	flags.Parse(args)
+
+	if *input == "" {
+		flags.Usage()
+		return fmt.Errorf("input file not specified")
+	}
+
+	if *speed <= 0 {
+		return fmt.Errorf("speed must be positive")
+	}
+
+	return editCast(*input, *output, func(src player.FrameSource) player.FrameSource {
+		// typing is normalized first because it relies on original gaps, hold is applied to final timeline
+		src = player.NormalizeTyping(src, typing.Seconds())
+		src = player.LimitIdle(src, idleLimit.Seconds())
+		src = player.ChangeSpeed(src, *speed)
+
+		if *coalesce > 0 {
+			src = player.Coalesce(src, coalesce.Seconds())
+		}
+
+		return player.HoldEnd(src, hold.Seconds())
+	})
+}
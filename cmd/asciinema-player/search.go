@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func searchCommand(args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	ignoreCase := flags.Bool("i", false, "ignore case")
+	fixed := flags.Bool("F", false, "treat pattern as literal string")
+	jsonOutput := flags.Bool("json", false, "print hits as JSON")
+	flags.BoolVar(&lenient, "lenient", false, lenientUsage)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: search [flags] pattern file.cast|directory ...")
+		fmt.Fprintln(flags.Output(), "Directories are searched recursively for .cast files.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("pattern and input files must be specified")
+	}
+
+	expr := flags.Arg(0)
+	if *fixed {
+		expr = regexp.QuoteMeta(expr)
+	}
+
+	if *ignoreCase {
+		expr = "(?i)" + expr
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := expandInputs(flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	type reportEntry struct {
+		File  string  `json:"file"`
+		Time  float64 `json:"time"`
+		Match string  `json:"match"`
+		Line  string  `json:"line"`
+	}
+
+	entries := []reportEntry{}
+
+	for _, input := range inputs {
+		src, closer, err := openCast(input)
+		if err != nil {
+			return err
+		}
+
+		hits, err := player.Search(src, pattern)
+		closer.Close()
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", input, err)
+		}
+
+		reportSkipped(input, src)
+
+		for _, hit := range hits {
+			if !*jsonOutput {
+				fmt.Printf("%s:%s: %s\n", input, seconds(hit.Time), hit.Line)
+			}
+
+			entries = append(entries, reportEntry{File: input, Time: hit.Time, Match: hit.Match, Line: hit.Line})
+		}
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(entries)
+	}
+
+	return nil
+}
+
+// expandInputs replaces directories with .cast files inside them.
+func expandInputs(locations []string) ([]string, error) {
+	var inputs []string
+
+	for _, location := range locations {
+		info, err := os.Stat(location)
+		if err != nil || !info.IsDir() {
+			inputs = append(inputs, location) // let opener handle URLs, IDs and errors
+			continue
+		}
+
+		err = filepath.WalkDir(location, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if filepath.Ext(path) == ".cast" && d.Type().IsRegular() {
+				inputs = append(inputs, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/sshterm"
+)
+
+func sshCommand(args []string) error {
+	flags := flag.NewFlagSet("ssh", flag.ExitOnError)
+	listenAddr := flags.String("listen", ":2222", "listen address")
+	hostKeyPath := flags.String("host-key", "", "path to PEM-encoded host private key, temporary key is generated if empty")
+	dir := flags.String("dir", ".", "directory with recordings")
+	maxWait := flags.Duration("maxWait", 2*time.Second, "maximum time between frames")
+	speed := flags.Float64("speed", 1, "speed adjustment: >1 - faster, <1 - slower")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: ssh [flags]")
+		fmt.Fprintln(flags.Output(), "Serves recordings of directory over SSH without authentication, client chooses recording from menu.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	signer, err := loadHostKey(*hostKeyPath)
+	if err != nil {
+		return err
+	}
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	lis, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Listening on %s, host key %s\n", lis.Addr(), ssh.FingerprintSHA256(signer.PublicKey()))
+
+	srv := &sshterm.Server{
+		Config:  config,
+		FS:      os.DirFS(*dir),
+		Options: []player.Option{player.WithMaxWait(*maxWait), player.WithSpeed(*speed)},
+	}
+
+	return srv.Serve(lis)
+}
+
+func loadHostKey(path string) (ssh.Signer, error) {
+	if path == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("generate host key failed: %w", err)
+		}
+
+		return ssh.NewSignerFromKey(key)
+	}
+
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse host key failed: %w", err)
+	}
+
+	return signer, nil
+}
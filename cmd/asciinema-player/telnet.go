@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/telnetterm"
+)
+
+func telnetCommand(args []string) error {
+	flags := flag.NewFlagSet("telnet", flag.ExitOnError)
+	listenAddr := flags.String("listen", ":2323", "listen address")
+	dir := flags.String("dir", ".", "directory with recordings")
+	cast := flags.String("cast", "", "recording played to every client instead of menu")
+	width := flags.Int("width", telnetterm.DefaultWidth, "terminal width of clients not reporting window size")
+	height := flags.Int("height", telnetterm.DefaultHeight, "terminal height of clients not reporting window size")
+	maxWait := flags.Duration("maxWait", 2*time.Second, "maximum time between frames")
+	speed := flags.Float64("speed", 1, "speed adjustment: >1 - faster, <1 - slower")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: telnet [flags]")
+		fmt.Fprintln(flags.Output(), "Serves recordings to telnet and raw TCP (i.e. netcat) clients, client chooses recording from menu.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	srv := &telnetterm.Server{
+		FS:          os.DirFS(*dir),
+		Options:     []player.Option{player.WithMaxWait(*maxWait), player.WithSpeed(*speed)},
+		TermOptions: []telnetterm.Option{telnetterm.WithDefaultSize(*width, *height)},
+	}
+
+	if *cast != "" {
+		srv.FS, srv.Cast = os.DirFS(filepath.Dir(*cast)), filepath.Base(*cast)
+	}
+
+	lis, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Listening on", lis.Addr())
+
+	return srv.Serve(lis)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func validateCommand(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "print problems as JSON")
+	maxGap := flags.Duration("max-gap", time.Duration(player.DefaultMaxGap)*time.Second, "gap between frames reported as suspicious")
+	strict := flags.Bool("strict", false, "fail on warnings too")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: validate [flags] input1.cast input2.cast ...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("input files not specified")
+	}
+
+	type reportEntry struct {
+		File     string          `json:"file"`
+		Line     int             `json:"line"`
+		Column   int             `json:"column"`
+		Severity player.Severity `json:"severity"`
+		Message  string          `json:"message"`
+	}
+
+	var (
+		entries                  = []reportEntry{}
+		errorCount, warningCount int
+	)
+
+	for _, input := range flags.Args() {
+		file, err := openInput(input)
+		if err != nil {
+			return err
+		}
+
+		problems, err := player.Validate(file, maxGap.Seconds())
+		file.Close()
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", input, err)
+		}
+
+		for _, p := range problems {
+			switch p.Severity {
+			case player.SeverityError:
+				errorCount++
+			case player.SeverityWarning:
+				warningCount++
+			}
+
+			entries = append(entries, reportEntry{File: input, Line: p.Line, Column: p.Column, Severity: p.Severity, Message: p.Message})
+		}
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(entries); err != nil {
+			return err
+		}
+	} else {
+		for _, e := range entries {
+			fmt.Printf("%s:%d:%d: %s: %s\n", e.File, e.Line, e.Column, e.Severity, e.Message)
+		}
+	}
+
+	if errorCount > 0 || (*strict && warningCount > 0) {
+		return fmt.Errorf("%d errors, %d warnings found", errorCount, warningCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package player
+
+import (
+	"github.com/xakep666/asciinema-player/v3/internal/ansi"
+)
+
+const (
+	// DefaultWidth is a terminal width used when it can't be inferred.
+	DefaultWidth = 80
+
+	// DefaultHeight is a terminal height used when it can't be inferred.
+	DefaultHeight = 24
+)
+
+const tabWidth = 8
+
+// InferDimensions guesses terminal size using output frames of FrameSource.
+// It's useful for formats that don't store terminal size (i.e. ttyrec).
+// Resize frames and requests, cursor positioning, scroll regions and line lengths are taken into account.
+// Result is never less than DefaultWidth x DefaultHeight. Frame source is consumed.
+func InferDimensions(src FrameSource) (width, height int, err error) {
+	var (
+		parser ansi.Parser
+		col    int // 1-based column of last printed character
+	)
+
+	width, height = DefaultWidth, DefaultHeight
+
+	fit := func(w, h int) {
+		if w > width {
+			width = w
+		}
+
+		if h > height {
+			height = h
+		}
+	}
+
+	handle := func(seq *ansi.Sequence) {
+		switch seq.Kind {
+		case ansi.Print:
+			col++
+
+			// Shells often print line of spaces relying on autowrap, so they don't count.
+			// Row is not tracked because scrolling makes it unknown after line feeds.
+			if seq.Rune != ' ' {
+				fit(col, 0)
+			}
+		case ansi.Control:
+			switch seq.Byte {
+			case '\r':
+				col = 0
+			case '\b':
+				if col > 0 {
+					col--
+				}
+			case '\t':
+				col += tabWidth - col%tabWidth
+			}
+		case ansi.CSI:
+			if seq.Prefix != 0 || len(seq.Intermediates) > 0 {
+				return
+			}
+
+			switch seq.Final {
+			case 'H', 'f': // cursor position
+				col = seq.Param(1, 1) - 1
+				fit(col+1, seq.Param(0, 1))
+			case 'C': // cursor forward
+				col += seq.Param(0, 1)
+			case 'D': // cursor backward
+				if col -= seq.Param(0, 1); col < 0 {
+					col = 0
+				}
+			case 'G', '`': // cursor horizontal absolute
+				col = seq.Param(0, 1) - 1
+				fit(col+1, 0)
+			case 'd': // line position absolute
+				fit(0, seq.Param(0, 1))
+			case 'r': // scroll region
+				fit(0, seq.Param(1, 0))
+			case 't': // window manipulation, 8 is "resize text area"
+				if seq.Param(0, 0) == 8 {
+					fit(seq.Param(2, 0), seq.Param(1, 0))
+				}
+			}
+		}
+	}
+
+	for src.Next() {
+		frame := src.Frame()
+
+		switch frame.Type {
+		case OutputFrame:
+			parser.Feed(frame.Data, handle)
+		case ResizeFrame:
+			if w, h, err := ParseResizeData(frame.Data); err == nil {
+				fit(w, h)
+			}
+		}
+	}
+
+	return width, height, src.Err()
+}
@@ -0,0 +1,195 @@
+package player
+
+import (
+	"math"
+)
+
+// resetSequence is a full terminal reset (RIS). It's inserted between concatenated recordings.
+const resetSequence = "\033c"
+
+// Cut returns FrameSource containing frames within [start, end) time range, timeline is shifted to begin at zero.
+// Output preceding range is folded into single frame at zero time so screen state at start is restored.
+// Non-positive end means end of recording.
+func Cut(src FrameSource, start, end float64) FrameSource {
+	cut := Splice(src, 0, start)
+	if end <= 0 {
+		return cut
+	}
+
+	return Clip(cut, 0, end-math.Max(start, 0))
+}
+
+// Trim returns FrameSource without first head and last tail seconds of recording.
+// Output of removed head is folded into single frame at zero time so screen state is restored.
+func Trim(src FrameSource, head, tail float64) FrameSource {
+	trimmed := Splice(src, 0, head)
+	if tail <= 0 {
+		return trimmed
+	}
+
+	return &tailTrimFrameSource{src: trimmed, tail: tail}
+}
+
+// Splice returns FrameSource without frames within [start, end) time range. Later frames are shifted back by range length.
+// Output of removed range is folded into single frame at start time so screen state stays consistent.
+// Last resize within range is kept too.
+func Splice(src FrameSource, start, end float64) FrameSource {
+	start = math.Max(start, 0)
+	if end <= start {
+		return src
+	}
+
+	// parts are read one after another from the same source
+	shared := &sharedFrameSource{FrameSource: src}
+
+	parts := []FrameSource{
+		TimeShift(fold(Clip(shared, start, end)), start),
+		TimeShift(Clip(shared, end, 0), start),
+	}
+
+	if start > 0 {
+		parts = append([]FrameSource{Clip(shared, 0, start)}, parts...)
+	}
+
+	return &headerFrameSource{
+		FrameSource: chain(parts...),
+		header: func(Header) Header {
+			hdr := src.Header()
+			if hdr.Timestamp != 0 && start == 0 {
+				hdr.Timestamp += int64(end)
+			}
+
+			return hdr
+		},
+	}
+}
+
+// sharedFrameSource lets consumer return last read frame, so next consumer of the same source reads it again.
+type sharedFrameSource struct {
+	FrameSource
+	unread bool
+}
+
+func (s *sharedFrameSource) Next() bool {
+	if s.unread {
+		s.unread = false
+		return true
+	}
+
+	return s.FrameSource.Next()
+}
+
+func (s *sharedFrameSource) Unread() { s.unread = true }
+
+// fold returns FrameSource with output of source folded into single frame at zero time, so writing it restores
+// screen state at end of source. Last resize is kept before it, other frames are dropped.
+func fold(src FrameSource) FrameSource {
+	return &foldFrameSource{src: src}
+}
+
+type foldFrameSource struct {
+	src   FrameSource
+	queue []Frame
+	frame Frame
+	done  bool
+}
+
+func (s *foldFrameSource) Header() Header { return s.src.Header() }
+
+func (s *foldFrameSource) Next() bool {
+	if !s.done {
+		s.done = true
+
+		var output, resize []byte
+
+		for s.src.Next() {
+			switch frame := s.src.Frame(); frame.Type {
+			case OutputFrame:
+				output = append(output, frame.Data...)
+			case ResizeFrame:
+				resize = append(resize[:0], frame.Data...)
+			}
+		}
+
+		if resize != nil {
+			s.queue = append(s.queue, Frame{Type: ResizeFrame, Data: resize})
+		}
+
+		if output != nil {
+			s.queue = append(s.queue, Frame{Type: OutputFrame, Data: output})
+		}
+	}
+
+	if len(s.queue) == 0 {
+		return false
+	}
+
+	s.frame, s.queue = s.queue[0], s.queue[1:]
+
+	return true
+}
+
+func (s *foldFrameSource) Frame() Frame { return s.frame }
+
+func (s *foldFrameSource) Err() error { return s.src.Err() }
+
+// chain returns FrameSource playing sources one after another as is, unlike Concat timeline and terminal aren't adjusted.
+func chain(sources ...FrameSource) FrameSource {
+	return &chainFrameSource{FrameSource: sources[0], sources: sources}
+}
+
+type chainFrameSource struct {
+	FrameSource // current source
+	sources     []FrameSource
+}
+
+func (s *chainFrameSource) Next() bool {
+	for {
+		if s.FrameSource.Next() {
+			return true
+		}
+
+		if s.FrameSource.Err() != nil || len(s.sources) == 1 {
+			return false
+		}
+
+		s.sources = s.sources[1:]
+		s.FrameSource = s.sources[0]
+	}
+}
+
+// tailTrimFrameSource drops frames within last tail seconds. It buffers frames within tail window.
+type tailTrimFrameSource struct {
+	src  FrameSource
+	tail float64
+
+	queue    []Frame
+	frame    Frame
+	lastTime float64
+	done     bool
+}
+
+func (s *tailTrimFrameSource) Header() Header { return s.src.Header() }
+
+func (s *tailTrimFrameSource) Next() bool {
+	for {
+		if len(s.queue) > 0 && s.queue[0].Time <= s.lastTime-s.tail {
+			s.frame, s.queue = s.queue[0], s.queue[1:]
+			return true
+		}
+
+		if s.done || !s.src.Next() {
+			s.done = true
+			return false
+		}
+
+		frame := s.src.Frame()
+		frame.Data = append([]byte(nil), frame.Data...)
+		s.queue = append(s.queue, frame)
+		s.lastTime = frame.Time
+	}
+}
+
+func (s *tailTrimFrameSource) Frame() Frame { return s.frame }
+
+func (s *tailTrimFrameSource) Err() error { return s.src.Err() }
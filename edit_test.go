@@ -0,0 +1,77 @@
+package player_test
+
+import (
+	"reflect"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const editTestCast = `{"version":2,"width":80,"height":24,"timestamp":1000}
+[1,"o","a"]
+[2,"i","b"]
+[2,"o","b"]
+[3,"r","100x30"]
+[3,"o","c"]
+[4,"m","marker"]
+[5,"o","d"]
+`
+
+func TestCut(t *testing.T) {
+	src := player.Cut(mustSourceFromString(t, editTestCast), 2.5, 4.5)
+
+	if hdr := src.Header(); hdr.Timestamp != 1002 {
+		t.Fatalf("Unexpected header: %+v", hdr)
+	}
+
+	expected := []player.Frame{
+		{Time: 0, Type: player.OutputFrame, Data: []byte("ab")},
+		{Time: 0.5, Type: player.ResizeFrame, Data: []byte("100x30")},
+		{Time: 0.5, Type: player.OutputFrame, Data: []byte("c")},
+		{Time: 1.5, Type: player.MarkerFrame, Data: []byte("marker")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	src := player.Trim(mustSourceFromString(t, editTestCast), 1.5, 1.5)
+
+	expected := []player.Frame{
+		{Time: 0, Type: player.OutputFrame, Data: []byte("a")},
+		{Time: 0.5, Type: player.InputFrame, Data: []byte("b")},
+		{Time: 0.5, Type: player.OutputFrame, Data: []byte("b")},
+		{Time: 1.5, Type: player.ResizeFrame, Data: []byte("100x30")},
+		{Time: 1.5, Type: player.OutputFrame, Data: []byte("c")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestSplice(t *testing.T) {
+	src := player.Splice(mustSourceFromString(t, editTestCast), 1.5, 3.5)
+
+	expected := []player.Frame{
+		{Time: 1, Type: player.OutputFrame, Data: []byte("a")},
+		{Time: 1.5, Type: player.ResizeFrame, Data: []byte("100x30")},
+		{Time: 1.5, Type: player.OutputFrame, Data: []byte("bc")},
+		{Time: 2, Type: player.MarkerFrame, Data: []byte("marker")},
+		{Time: 3, Type: player.OutputFrame, Data: []byte("d")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+
+	// removed range at the end
+	src = player.Splice(mustSourceFromString(t, editTestCast), 4.5, 10)
+
+	frames := readFrames(t, src)
+	if last := frames[len(frames)-1]; last.Time != 4.5 || string(last.Data) != "d" {
+		t.Fatalf("Unexpected last frame: %+v", last)
+	}
+}
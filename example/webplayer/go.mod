@@ -2,15 +2,14 @@ module github.com/xakep666/asciinema-player/example/webplayer
 
 go 1.17
 
-require (
-	github.com/xakep666/asciinema-player/v3 v3.0.0
-	nhooyr.io/websocket v1.8.7
-)
+require github.com/xakep666/asciinema-player/v3 v3.0.0
 
 require (
 	github.com/klauspost/compress v1.10.3 // indirect
+	golang.org/x/image v0.5.0 // indirect
 	golang.org/x/sys v0.1.0 // indirect
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211 // indirect
+	nhooyr.io/websocket v1.8.7 // indirect
 )
 
 replace github.com/xakep666/asciinema-player/v3 v3.0.0 => ../../
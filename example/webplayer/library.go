@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/render"
+	"github.com/xakep666/asciinema-player/v3/vt"
+	"github.com/xakep666/asciinema-player/v3/wsterm"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// Entry is an indexed recording.
+type Entry struct {
+	File     string    `json:"file"`
+	Title    string    `json:"title,omitempty"`
+	Duration float64   `json:"duration"`
+	Width    int       `json:"width"`
+	Height   int       `json:"height"`
+	Bytes    int64     `json:"bytes"`
+	Date     time.Time `json:"date"` // recording time from header or modification time of file
+	Error    string    `json:"error,omitempty"`
+
+	modTime   time.Time
+	markers   []wsterm.Marker
+	thumbnail []byte // SVG of final screen
+}
+
+// Library indexes casts of file system: header metadata, duration, markers and thumbnail of final screen.
+// Index is refreshed when files are added, changed or removed.
+type Library struct {
+	fsys fs.FS
+
+	mu      sync.RWMutex
+	entries map[string]*Entry
+}
+
+func NewLibrary(fsys fs.FS) *Library {
+	return &Library{fsys: fsys, entries: make(map[string]*Entry)}
+}
+
+// Refresh indexes new and changed casts and removes deleted ones.
+func (l *Library) Refresh() error {
+	found := make(map[string]struct{})
+
+	err := fs.WalkDir(l.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path.Ext(name) != ".cast" || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		found[name] = struct{}{}
+
+		l.mu.RLock()
+		entry, ok := l.entries[name]
+		l.mu.RUnlock()
+
+		if ok && entry.modTime.Equal(info.ModTime()) && entry.Bytes == info.Size() {
+			return nil
+		}
+
+		entry = l.index(name, info)
+
+		l.mu.Lock()
+		l.entries[name] = entry
+		l.mu.Unlock()
+
+		return nil
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for name := range l.entries {
+		if _, ok := found[name]; !ok {
+			delete(l.entries, name)
+		}
+	}
+
+	return err
+}
+
+// Watch refreshes index with given interval until context is done.
+func (l *Library) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := l.Refresh(); err != nil {
+				log.Println("Library refresh failed:", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// index reads metadata of cast and renders thumbnail. Broken casts are indexed with error.
+func (l *Library) index(name string, info fs.FileInfo) *Entry {
+	entry := &Entry{File: name, Bytes: info.Size(), Date: info.ModTime(), modTime: info.ModTime()}
+
+	if err := l.indexCast(entry); err != nil {
+		entry.Error = err.Error()
+	}
+
+	return entry
+}
+
+func (l *Library) indexCast(entry *Entry) error {
+	file, err := l.fsys.Open(entry.File)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	src, err := player.NewStreamFrameSource(file, player.WithLenientParsing())
+	if err != nil {
+		return err
+	}
+
+	hdr := src.Header()
+
+	entry.Title, entry.Width, entry.Height = hdr.Title, hdr.Width, hdr.Height
+	if hdr.Timestamp > 0 {
+		entry.Date = time.Unix(hdr.Timestamp, 0)
+	}
+
+	screen := vt.New(hdr.Width, hdr.Height)
+
+	for src.Next() {
+		frame := src.Frame()
+		entry.Duration = frame.Time
+
+		switch frame.Type {
+		case player.OutputFrame:
+			screen.Write(frame.Data)
+		case player.ResizeFrame:
+			if width, height, err := player.ParseResizeData(frame.Data); err == nil {
+				screen.Resize(width, height)
+			}
+		case player.MarkerFrame:
+			entry.markers = append(entry.markers, wsterm.Marker{Time: frame.Time, Label: string(frame.Data)})
+		}
+	}
+
+	if err = src.Err(); err != nil {
+		return err
+	}
+
+	var thumbnail bytes.Buffer
+	if err = render.SVG(&thumbnail, screen, castPalette(hdr.Theme)); err != nil {
+		return err
+	}
+
+	entry.thumbnail = thumbnail.Bytes()
+
+	return nil
+}
+
+// Has returns true if cast is indexed.
+func (l *Library) Has(name string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.entries[name]
+
+	return ok
+}
+
+// Metadata returns duration, markers and title of indexed cast for seek bar and chapters.
+func (l *Library) Metadata(name string) (*wsterm.Metadata, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entry, ok := l.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("requested file was not found: %w", fs.ErrNotExist)
+	}
+
+	if entry.Error != "" {
+		return nil, fmt.Errorf("broken cast: %s", entry.Error)
+	}
+
+	return &wsterm.Metadata{Duration: entry.Duration, Markers: entry.markers, Title: entry.Title}, nil
+}
+
+// Names returns sorted names of indexed casts.
+func (l *Library) Names() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	names := make([]string, 0, len(l.entries))
+	for name := range l.entries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Query returns entries whose file name or title contains text (case-insensitive) sorted by given field.
+func (l *Library) Query(text, sortBy string, desc bool) ([]Entry, error) {
+	less, ok := entryOrders[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+
+	text = strings.ToLower(text)
+
+	l.mu.RLock()
+
+	entries := make([]Entry, 0, len(l.entries))
+	for _, entry := range l.entries {
+		if strings.Contains(strings.ToLower(entry.File), text) || strings.Contains(strings.ToLower(entry.Title), text) {
+			entries = append(entries, *entry)
+		}
+	}
+
+	l.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if desc {
+			i, j = j, i
+		}
+
+		if less(&entries[i], &entries[j]) {
+			return true
+		}
+
+		// file name makes order stable
+		return !less(&entries[j], &entries[i]) && entries[i].File < entries[j].File
+	})
+
+	return entries, nil
+}
+
+var entryOrders = map[string]func(a, b *Entry) bool{
+	"file":     func(a, b *Entry) bool { return a.File < b.File },
+	"title":    func(a, b *Entry) bool { return a.Title < b.Title },
+	"duration": func(a, b *Entry) bool { return a.Duration < b.Duration },
+	"date":     func(a, b *Entry) bool { return a.Date.Before(b.Date) },
+	"bytes":    func(a, b *Entry) bool { return a.Bytes < b.Bytes },
+}
+
+// Thumbnail returns SVG thumbnail of cast.
+func (l *Library) Thumbnail(name string) ([]byte, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entry, ok := l.entries[name]
+	if !ok || entry.thumbnail == nil {
+		return nil, false
+	}
+
+	return entry.thumbnail, true
+}
+
+// ServeHTTP lists casts. Query parameters: "q" - text to search in file name and title, "sort" - field to sort by
+// (file, title, duration, date, bytes), "order" - "asc" or "desc", "page" (from 1) and "per_page".
+func (l *Library) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = "file"
+	}
+
+	page, err := intParam(query.Get("page"), 1)
+	if err != nil || page < 1 {
+		http.Error(w, "invalid page", http.StatusBadRequest)
+		return
+	}
+
+	perPage, err := intParam(query.Get("per_page"), defaultPerPage)
+	if err != nil || perPage < 1 || perPage > maxPerPage {
+		http.Error(w, "invalid per_page", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := l.Query(query.Get("q"), sortBy, query.Get("order") == "desc")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	total := len(entries)
+
+	// huge page would overflow multiplication
+	from := total
+	if page-1 <= total/perPage {
+		from = (page - 1) * perPage
+	}
+
+	if from > total {
+		from = total
+	}
+
+	to := from + perPage
+	if to > total {
+		to = total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+		"entries":  entries[from:to],
+	})
+}
+
+// ServeThumbnail serves SVG thumbnail of cast from "file" parameter.
+func (l *Library) ServeThumbnail(w http.ResponseWriter, r *http.Request) {
+	thumbnail, ok := l.Thumbnail(r.URL.Query().Get("file"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(thumbnail)
+}
+
+func intParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLibrary_ServeHTTP(t *testing.T) {
+	lib := NewLibrary(fstest.MapFS{
+		"a.cast": {Data: []byte(`{"version":2,"width":20,"height":3}` + "\n")},
+		"b.cast": {Data: []byte(`{"version":2,"width":20,"height":3}` + "\n")},
+		"c.cast": {Data: []byte(`{"version":2,"width":20,"height":3}` + "\n")},
+	})
+	if err := lib.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %s", err)
+	}
+
+	for _, tc := range []struct {
+		query   string
+		status  int
+		entries int
+	}{
+		{"", http.StatusOK, 3},
+		{"per_page=2&page=2", http.StatusOK, 1},
+		{"per_page=2&page=3", http.StatusOK, 0},
+		{"per_page=2&page=" + strconv.Itoa(math.MaxInt), http.StatusOK, 0},
+		{"page=0", http.StatusBadRequest, 0},
+	} {
+		rec := httptest.NewRecorder()
+		lib.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/library?"+tc.query, nil))
+
+		if rec.Code != tc.status {
+			t.Fatalf("%s: unexpected status %d: %s", tc.query, rec.Code, rec.Body.String())
+		}
+
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		var body struct {
+			Total   int               `json:"total"`
+			Entries []json.RawMessage `json:"entries"`
+		}
+
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decode failed: %s", tc.query, err)
+		}
+
+		if body.Total != 3 || len(body.Entries) != tc.entries {
+			t.Fatalf("%s: unexpected total %d, entries %d", tc.query, body.Total, len(body.Entries))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/wsterm"
+)
+
+// startLive broadcasts cast that is still being written (i.e. by "asciinema rec") to live session viewers.
+func startLive(path string) (*wsterm.Hub, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open live cast: %w", err)
+	}
+
+	follow := player.NewFollowReader(f, 0)
+
+	src, err := player.NewStreamFrameSource(follow)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("read live cast: %w", err)
+	}
+
+	hub := wsterm.NewHub(src.Header())
+
+	go func() {
+		defer f.Close()
+		defer hub.Close()
+
+		if err := hub.Broadcast(context.Background(), src); err != nil {
+			log.Println("Live broadcast failed:", err)
+		}
+	}()
+
+	return hub, nil
+}
@@ -1,21 +1,31 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"flag"
-	"fmt"
 	"io/fs"
 	"log"
 	"net"
 	"net/http"
-	"os"
 	"path/filepath"
+	"runtime"
+	"time"
 )
 
 var (
-	rootDir    = flag.String("rootdir", ".", "Root directory for casts")
-	listenAddr = flag.String("listen-addr", "", "HTTP server listen address")
+	rootDir         = flag.String("rootdir", ".", "Root directory for casts")
+	listenAddr      = flag.String("listen-addr", "", "HTTP server listen address")
+	liveCast        = flag.String("live", "", "Cast that is still being written to broadcast as live session")
+	rescanInterval  = flag.Duration("rescan", 10*time.Second, "Interval of root directory rescan for added, changed and removed casts")
+	apiToken        = flag.String("api-token", "", "Bearer token of cast management API at /api/casts/, API is disabled if empty")
+	maxUpload       = flag.Int64("max-upload", DefaultMaxUpload, "Maximal size of uploaded cast in bytes")
+	redactUploads   = flag.Bool("redact-uploads", false, "Redact secrets in all uploaded casts, otherwise only if \"redact\" parameter is set")
+	renderCacheSize = flag.Int64("render-cache", 64<<20, "Size of cache of rendered images and transcripts in bytes")
+	renderWorkers   = flag.Int("render-concurrency", runtime.NumCPU(), "Maximal number of concurrent renders")
+	searchWorkers   = flag.Int("search-concurrency", runtime.NumCPU(), "Maximal number of concurrent searches")
+	partyKey        = flag.String("party-key", "", "Key giving controller role in watch parties, parties have no controller if empty")
 )
 
 //go:embed web
@@ -24,22 +34,51 @@ var webpages embed.FS
 func main() {
 	flag.Parse()
 
-	fsys, fset, err := getFSWithSet(*rootDir)
+	abs, err := filepath.Abs(*rootDir)
 	if err != nil {
-		log.Fatalln("Open root directory failed:", err)
+		log.Fatalln("Resolve root directory failed:", err)
 	}
 
-	flist := make([]string, 0, len(fset))
-	for fname := range fset {
-		flist = append(flist, fname)
+	storage := NewDirStorage(abs)
+
+	lib := NewLibrary(storage)
+	if err = lib.Refresh(); err != nil {
+		log.Fatalln("Index root directory failed:", err)
 	}
 
+	go lib.Watch(context.Background(), *rescanInterval)
+
 	mux := http.NewServeMux()
-	mux.Handle("/play", &TermHandler{FS: fsys, FileSet: fset})
+	mux.Handle("/play", newTermHandler(lib))
+	mux.Handle("/party", newPartyHandler(lib, *partyKey))
+	mux.Handle("/search", newSearchHandler(lib, *searchWorkers))
+	mux.Handle("/render", newRenderHandler(lib, *renderCacheSize, *renderWorkers))
+	mux.Handle("/library", lib)
+	mux.HandleFunc("/thumbnail", lib.ServeThumbnail)
+	if *apiToken != "" {
+		mux.Handle("/api/casts/", &ManageHandler{
+			Prefix:  "/api/casts/",
+			Storage: storage,
+			Library: lib,
+			Token:   *apiToken,
+			MaxSize: *maxUpload,
+			Redact:  *redactUploads,
+		})
+	}
+
 	mux.HandleFunc("/files", func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(map[string]interface{}{"files": flist})
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": lib.Names(), "live": *liveCast != ""})
 	})
 
+	if *liveCast != "" {
+		hub, err := startLive(*liveCast)
+		if err != nil {
+			log.Fatalln("Live session start failed:", err)
+		}
+
+		mux.Handle("/live", hub)
+	}
+
 	sub, _ := fs.Sub(webpages, "web")
 	mux.Handle("/", http.FileServer(http.FS(sub)))
 
@@ -54,27 +93,3 @@ func main() {
 		log.Fatalln("Serve failed:", err)
 	}
 }
-
-func getFSWithSet(rootDir string) (fs.FS, map[string]struct{}, error) {
-	abs, err := filepath.Abs(rootDir)
-	if err != nil {
-		return nil, nil, fmt.Errorf("resolve absoulete path failed: %s", err)
-	}
-
-	osfs := os.DirFS(abs)
-	fset := make(map[string]struct{})
-
-	err = fs.WalkDir(osfs, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if filepath.Ext(path) == ".cast" && d.Type().IsRegular() {
-			fset[path] = struct{}{}
-		}
-
-		return nil
-	})
-
-	return osfs, fset, err
-}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// DefaultMaxUpload is a default limit of uploaded cast size in bytes.
+const DefaultMaxUpload = 50 << 20
+
+// ManageHandler is an API managing casts of storage, cast name is a path after Prefix:
+//
+//	GET    - download cast
+//	PUT    - upload cast, it's validated and redacted if Redact is set or "redact" parameter is true
+//	PATCH  - rename cast and/or set its title, JSON body {"name": "new.cast", "title": "New title"}
+//	DELETE - remove cast
+//
+// Requests are authenticated with "Authorization: Bearer <Token>" header. Changes of the same cast are serialized.
+type ManageHandler struct {
+	Prefix  string
+	Storage Storage
+	Library *Library // refreshed after changes, may be nil
+	Token   string   // all requests are refused if empty
+	MaxSize int64    // DefaultMaxUpload if not positive
+	Redact  bool
+
+	mu    sync.Mutex
+	locks map[string]*nameLock
+}
+
+// nameLock serializes changes of cast, it's removed when nobody holds or waits for it.
+type nameLock struct {
+	sync.Mutex
+	refs int
+}
+
+type uploadResponse struct {
+	File       string              `json:"file"`
+	Problems   []string            `json:"problems,omitempty"` // validation warnings, or errors if cast is refused
+	Redactions []redactionResponse `json:"redactions,omitempty"`
+}
+
+type redactionResponse struct {
+	Rule   string           `json:"rule"`
+	Type   player.FrameType `json:"type"`
+	Time   float64          `json:"time"`
+	Length int              `json:"length"`
+}
+
+type updateRequest struct {
+	Name  *string `json:"name"`
+	Title *string `json:"title"`
+}
+
+func (h *ManageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, h.Prefix)
+	if err := checkName(name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.download(w, name)
+	case http.MethodPut:
+		h.upload(w, r, name)
+	case http.MethodPatch:
+		h.update(w, r, name)
+	case http.MethodDelete:
+		unlock := h.lock(name)
+		defer unlock()
+
+		if err := h.Storage.Remove(name); err != nil {
+			h.fail(w, err)
+			return
+		}
+
+		h.refresh()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, PATCH, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *ManageHandler) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return h.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) == 1
+}
+
+func (h *ManageHandler) download(w http.ResponseWriter, name string) {
+	file, err := h.Storage.Open(name)
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(name)))
+	io.Copy(w, file)
+}
+
+// upload validates cast and puts it to storage. Casts with errors are refused, warnings are reported.
+func (h *ManageHandler) upload(w http.ResponseWriter, r *http.Request, name string) {
+	maxSize := h.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxUpload
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("read failed: %s", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	redact := h.Redact
+	if value := r.URL.Query().Get("redact"); value != "" {
+		requested, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "invalid redact parameter", http.StatusBadRequest)
+			return
+		}
+
+		redact = redact || requested // uploader can't disable redaction forced by server
+	}
+
+	problems, err := player.Validate(bytes.NewReader(body), 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("validation failed: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	resp := uploadResponse{File: name}
+	valid := true
+
+	for _, problem := range problems {
+		resp.Problems = append(resp.Problems, problem.String())
+		valid = valid && problem.Severity != player.SeverityError
+	}
+
+	if !valid {
+		writeJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	if redact {
+		var redactions []player.Redaction
+		if body, redactions, err = redactCast(body); err != nil {
+			http.Error(w, fmt.Sprintf("redaction failed: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		for _, r := range redactions {
+			resp.Redactions = append(resp.Redactions, redactionResponse(r))
+		}
+	}
+
+	unlock := h.lock(name)
+	defer unlock()
+
+	status := http.StatusOK
+	if _, err = fs.Stat(h.Storage, name); errors.Is(err, fs.ErrNotExist) {
+		status = http.StatusCreated
+	}
+
+	if err = h.Storage.Put(name, bytes.NewReader(body)); err != nil {
+		h.fail(w, err)
+		return
+	}
+
+	h.refresh()
+	writeJSON(w, status, resp)
+}
+
+// update renames cast and then sets its title in cast header.
+func (h *ManageHandler) update(w http.ResponseWriter, r *http.Request, name string) {
+	var req updateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.Name != nil && *req.Name != name {
+		if err := checkName(*req.Name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		unlock := h.lock(name, *req.Name)
+		defer unlock()
+
+		if err := h.Storage.Rename(name, *req.Name); err != nil {
+			h.fail(w, err)
+			return
+		}
+
+		name = *req.Name
+	} else {
+		unlock := h.lock(name)
+		defer unlock()
+	}
+
+	if req.Title != nil {
+		if err := h.setTitle(name, *req.Title); err != nil {
+			h.fail(w, err)
+			return
+		}
+	}
+
+	h.refresh()
+	writeJSON(w, http.StatusOK, map[string]string{"file": name})
+}
+
+// setTitle patches title in header of cast, other header fields and frames are kept as is.
+func (h *ManageHandler) setTitle(name, title string) error {
+	cast, err := fs.ReadFile(h.Storage, name)
+	if err != nil {
+		return err
+	}
+
+	headerLine, frames := splitHeader(cast)
+
+	var header map[string]json.RawMessage
+	if err = json.Unmarshal(headerLine, &header); err != nil {
+		return fmt.Errorf("header decode failed: %w", err)
+	}
+
+	if title == "" {
+		delete(header, "title")
+	} else if header["title"], err = json.Marshal(title); err != nil {
+		return err
+	}
+
+	if headerLine, err = json.Marshal(header); err != nil {
+		return err
+	}
+
+	return h.Storage.Put(name, io.MultiReader(bytes.NewReader(headerLine), bytes.NewReader(frames)))
+}
+
+// lock acquires locks of names in sorted order, so concurrent renames don't deadlock. Returned function releases them.
+func (h *ManageHandler) lock(names ...string) (unlock func()) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	var (
+		keys  []string
+		locks []*nameLock
+	)
+
+	h.mu.Lock()
+
+	if h.locks == nil {
+		h.locks = make(map[string]*nameLock)
+	}
+
+	for i, name := range names {
+		if i > 0 && name == names[i-1] {
+			continue
+		}
+
+		l, ok := h.locks[name]
+		if !ok {
+			l = &nameLock{}
+			h.locks[name] = l
+		}
+
+		l.refs++
+		keys, locks = append(keys, name), append(locks, l)
+	}
+
+	h.mu.Unlock()
+
+	for _, l := range locks {
+		l.Lock()
+	}
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		for i, l := range locks {
+			l.Unlock()
+
+			if l.refs--; l.refs == 0 {
+				delete(h.locks, keys[i])
+			}
+		}
+	}
+}
+
+func (h *ManageHandler) refresh() {
+	if h.Library == nil {
+		return
+	}
+
+	if err := h.Library.Refresh(); err != nil {
+		log.Println("Library refresh failed:", err)
+	}
+}
+
+func (h *ManageHandler) fail(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, fs.ErrExist):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Println("Cast management failed:", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// redactCast replaces secrets detected by default rules. Header is kept as is, so fields unknown to player are preserved.
+func redactCast(cast []byte) ([]byte, []player.Redaction, error) {
+	src, err := player.NewStreamFrameSource(bytes.NewReader(cast))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redactor := player.NewRedactFrameSource(src, player.DefaultRedactRules()...)
+
+	var buf bytes.Buffer
+	if err = writeCast(&buf, src.Header(), redactor); err != nil {
+		return nil, nil, err
+	}
+
+	headerLine, _ := splitHeader(cast)
+	if !json.Valid(headerLine) {
+		return buf.Bytes(), redactor.Redactions(), nil // header spans lines, written one is used
+	}
+
+	_, frames := splitHeader(buf.Bytes())
+
+	return append(headerLine, frames...), redactor.Redactions(), nil
+}
+
+// splitHeader splits cast to header line and frames starting with line break.
+func splitHeader(cast []byte) (headerLine, frames []byte) {
+	if i := bytes.IndexByte(cast, '\n'); i >= 0 {
+		return cast[:i:i], cast[i:]
+	}
+
+	return cast, nil
+}
+
+func writeCast(w io.Writer, hdr player.Header, src player.FrameSource) error {
+	writer, err := player.NewStreamFrameWriter(w, hdr)
+	if err != nil {
+		return err
+	}
+
+	return player.CopyFrames(writer, src)
+}
+
+// checkName checks that name is valid for storage and names a cast.
+func checkName(name string) error {
+	if !fs.ValidPath(name) || name == "." || path.Ext(name) != ".cast" {
+		return fmt.Errorf("invalid cast name %q: slash-separated relative path with .cast extension expected", name)
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
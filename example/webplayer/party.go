@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/xakep666/asciinema-player/v3/wsterm"
+)
+
+// partyHandler runs shared playback ("watch party") per cast: first member starts party,
+// members joining later watch the same playback, party ends when all members leave.
+// Controller role is given by key, without key parties play from beginning with no controllers.
+// Deep link parameters of members are ignored since playback is shared.
+type partyHandler struct {
+	lib *Library
+	key string
+
+	mu      sync.Mutex
+	parties map[string]*wsterm.Party
+}
+
+func newPartyHandler(lib *Library, key string) *partyHandler {
+	return &partyHandler{
+		lib:     lib,
+		key:     key,
+		parties: make(map[string]*wsterm.Party),
+	}
+}
+
+func (h *partyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// rejected member must not start party
+	if h.key != "" {
+		if _, err := h.role(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	party, err := h.party(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, fs.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	party.ServeHTTP(w, r)
+}
+
+// party returns running party for requested cast or starts new one.
+func (h *partyHandler) party(r *http.Request) (*wsterm.Party, error) {
+	fileName := r.URL.Query().Get("file")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if party, ok := h.parties[fileName]; ok {
+		return party, nil
+	}
+
+	rec, err := openCast(h.lib, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var role func(r *http.Request) (wsterm.Role, error)
+	if h.key != "" {
+		role = h.role
+	}
+
+	party, err := wsterm.NewParty(*rec, role)
+	if err != nil {
+		rec.Close()
+		return nil, err
+	}
+
+	h.parties[fileName] = party
+
+	go func() {
+		if err := party.Play(); err != nil {
+			log.Printf("Party playback of %s failed: %s", fileName, err)
+		}
+
+		h.mu.Lock()
+		delete(h.parties, fileName)
+		h.mu.Unlock()
+	}()
+
+	return party, nil
+}
+
+func (h *partyHandler) role(r *http.Request) (wsterm.Role, error) {
+	key := r.URL.Query().Get("key")
+
+	switch {
+	case key == "":
+		return wsterm.Viewer, nil
+	case subtle.ConstantTimeCompare([]byte(key), []byte(h.key)) == 1:
+		return wsterm.Controller, nil
+	default:
+		return wsterm.Viewer, fmt.Errorf("invalid controller key")
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/render"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+const (
+	// defaultGIFMaxWait is a limit of idle pauses in GIF if cast has no idle_time_limit.
+	defaultGIFMaxWait = 5.
+
+	// gifHoldEnd is a time in seconds last screen of GIF is shown before loop restarts.
+	gifHoldEnd = 3.
+
+	maxFrameRate = 50
+
+	// maxRenderSize is a limit of render size in bytes.
+	maxRenderSize = 32 << 20
+)
+
+// renderFormats are content types of supported formats.
+var renderFormats = map[string]string{
+	"gif": "image/gif",
+	"svg": "image/svg+xml",
+	"png": "image/png",
+	"txt": "text/plain; charset=utf-8",
+}
+
+// renderOptions are options of rendering. Options not used by format are zero so they don't split cache.
+type renderOptions struct {
+	format    string
+	time      float64 // screen time for svg and png
+	speed     float64 // gif only
+	maxWait   float64 // gif only, negative means idle_time_limit of cast or defaultGIFMaxWait
+	frameRate int     // gif only
+}
+
+func (o renderOptions) String() string {
+	return fmt.Sprintf("%s;t=%g;speed=%g;max_wait=%g;fps=%d", o.format, o.time, o.speed, o.maxWait, o.frameRate)
+}
+
+// renderHandler renders cast from "file" parameter in format from "format" parameter for pages without JavaScript:
+//
+//	gif - animation, "speed", "max_wait" (limit of idle pauses in seconds) and "fps" parameters are supported
+//	svg, png - screen at time from "t" parameter (in seconds), final screen by default
+//	txt - text transcript with time of every line
+//
+// Results are cached by hash of cast and options, number of concurrent renders is limited.
+// Casts exceeding limits of render package are refused with 422 status, renders bigger than maxRenderSize with 413 status.
+type renderHandler struct {
+	lib     *Library
+	cache   *renderCache
+	sem     chan struct{}
+	maxSize int
+}
+
+func newRenderHandler(lib *Library, cacheSize int64, concurrency int) *renderHandler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &renderHandler{
+		lib:     lib,
+		cache:   newRenderCache(cacheSize),
+		sem:     make(chan struct{}, concurrency),
+		maxSize: maxRenderSize,
+	}
+}
+
+func (h *renderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fileName := r.URL.Query().Get("file")
+	if !h.lib.Has(fileName) {
+		http.NotFound(w, r)
+		return
+	}
+
+	opts, err := parseRenderOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cast, err := fs.ReadFile(h.lib.fsys, fileName)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("read failed: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	castHash := sha256.Sum256(cast)
+	keyHash := sha256.Sum256([]byte(hex.EncodeToString(castHash[:]) + ";" + opts.String()))
+	key := hex.EncodeToString(keyHash[:])
+
+	// URL is stable while cast may change, so clients revalidate with ETag
+	w.Header().Set("ETag", `"`+key+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Header.Get("If-None-Match") == `"`+key+`"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, ok := h.cache.get(key)
+	if !ok {
+		if data, err = h.render(r, key, cast, opts); err != nil {
+			switch {
+			case r.Context().Err() != nil:
+			case errors.Is(err, render.ErrTooLarge) || errors.Is(err, render.ErrTooManyFrames):
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			case errors.Is(err, errRenderTooLarge):
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			default:
+				log.Printf("Render of %s (%s) failed: %s", fileName, opts, err)
+				http.Error(w, fmt.Sprintf("render failed: %s", err), http.StatusInternalServerError)
+			}
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", renderFormats[opts.format])
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// render renders cast when one of concurrent renders is finished.
+func (h *renderHandler) render(r *http.Request, key string, cast []byte, opts renderOptions) ([]byte, error) {
+	select {
+	case h.sem <- struct{}{}:
+		defer func() { <-h.sem }()
+	case <-r.Context().Done():
+		return nil, r.Context().Err()
+	}
+
+	// the same render may be finished while waiting
+	if data, ok := h.cache.get(key); ok {
+		return data, nil
+	}
+
+	stream, err := player.NewStreamFrameSource(bytes.NewReader(cast))
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := stream.Header()
+	palette := castPalette(hdr.Theme)
+
+	var src player.FrameSource = stream
+
+	buf := limitedBuffer{limit: h.maxSize}
+
+	switch opts.format {
+	case "gif":
+		maxWait := opts.maxWait
+		if maxWait < 0 {
+			maxWait = hdr.IdleTimeLimit
+		}
+
+		if maxWait <= 0 {
+			maxWait = defaultGIFMaxWait
+		}
+
+		src = player.HoldEnd(player.ChangeSpeed(player.LimitIdle(src, maxWait), opts.speed), gifHoldEnd)
+		err = render.GIF(&buf, src, palette, opts.frameRate)
+	case "svg", "png":
+		var screen *vt.Screen
+		if screen, err = render.ScreenAt(src, opts.time); err != nil {
+			return nil, err
+		}
+
+		if opts.format == "svg" {
+			err = render.SVG(&buf, screen, palette)
+		} else {
+			err = render.PNG(&buf, screen, palette)
+		}
+	case "txt":
+		err = player.ExportTranscript(&buf, src)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	h.cache.put(key, buf.Bytes())
+
+	return buf.Bytes(), nil
+}
+
+func parseRenderOptions(r *http.Request) (renderOptions, error) {
+	query := r.URL.Query()
+	opts := renderOptions{format: query.Get("format")}
+
+	param := func(name string, def float64) (float64, error) {
+		value := query.Get(name)
+		if value == "" {
+			return def, nil
+		}
+
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 || math.IsNaN(v) {
+			return 0, fmt.Errorf("invalid %s parameter", name)
+		}
+
+		return v, nil
+	}
+
+	var err error
+
+	switch opts.format {
+	case "gif":
+		if opts.speed, err = param("speed", 1); err != nil || opts.speed == 0 {
+			return opts, fmt.Errorf("invalid speed parameter")
+		}
+
+		if opts.maxWait, err = param("max_wait", -1); err != nil {
+			return opts, err
+		}
+
+		frameRate, err := param("fps", render.DefaultFrameRate)
+		if err != nil || frameRate < 1 || frameRate > maxFrameRate {
+			return opts, fmt.Errorf("invalid fps parameter, 1-%d expected", maxFrameRate)
+		}
+
+		opts.frameRate = int(frameRate)
+	case "svg", "png":
+		if opts.time, err = param("t", math.Inf(1)); err != nil {
+			return opts, err
+		}
+	case "txt":
+	default:
+		return opts, fmt.Errorf("unknown format %q, gif, svg, png or txt expected", opts.format)
+	}
+
+	return opts, nil
+}
+
+// errRenderTooLarge is returned when render exceeds size limit.
+var errRenderTooLarge = fmt.Errorf("render is too large")
+
+// limitedBuffer is a buffer refusing writes beyond limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, fmt.Errorf("%w: %d bytes is maximal", errRenderTooLarge, b.limit)
+	}
+
+	return b.Buffer.Write(p)
+}
+
+// castPalette returns palette of cast theme. Broken theme doesn't break rendering, default palette is used.
+func castPalette(theme *player.Theme) render.Palette {
+	palette, err := render.PaletteFromTheme(theme)
+	if err != nil {
+		return render.DefaultPalette()
+	}
+
+	return palette
+}
+
+// renderCache keeps recently used renders up to given total size.
+type renderCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	order   *list.List // of *cacheItem, most recently used first
+	items   map[string]*list.Element
+}
+
+type cacheItem struct {
+	key  string
+	data []byte
+}
+
+func newRenderCache(maxSize int64) *renderCache {
+	return &renderCache{maxSize: maxSize, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *renderCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*cacheItem).data, true
+}
+
+// put adds render to cache evicting least recently used ones. Renders bigger than cache aren't kept.
+func (c *renderCache) put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[key]; ok || int64(len(data)) > c.maxSize {
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheItem{key: key, data: data})
+	c.size += int64(len(data))
+
+	for c.size > c.maxSize {
+		item := c.order.Remove(c.order.Back()).(*cacheItem)
+		delete(c.items, item.key)
+		c.size -= int64(len(item.data))
+	}
+}
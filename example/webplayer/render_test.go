@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRenderHandler(t *testing.T) {
+	lib := NewLibrary(fstest.MapFS{
+		"test.cast": {Data: []byte(`{"version":2,"width":20,"height":3}
+[0.5,"o","first\r\n"]
+[1.5,"o","second\r\n"]
+`)},
+		"huge.cast": {Data: []byte(`{"version":2,"width":10000,"height":3}` + "\n")},
+	})
+	if err := lib.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %s", err)
+	}
+
+	h := newRenderHandler(lib, 1<<20, 1)
+
+	for _, tc := range []struct {
+		query       string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"file=missing.cast&format=png", http.StatusNotFound, "", ""},
+		{"file=test.cast&format=bmp", http.StatusBadRequest, "", ""},
+		{"file=test.cast&format=gif&fps=100", http.StatusBadRequest, "", ""},
+		{"file=test.cast&format=gif&speed=2", http.StatusOK, "image/gif", "GIF89a"},
+		{"file=test.cast&format=png&t=1", http.StatusOK, "image/png", "PNG"},
+		{"file=test.cast&format=svg&t=1", http.StatusOK, "image/svg+xml", ">first<"},
+		{"file=test.cast&format=txt", http.StatusOK, "text/plain; charset=utf-8", "second"},
+		{"file=huge.cast&format=png", http.StatusUnprocessableEntity, "", "too large"},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render?"+tc.query, nil))
+
+		if rec.Code != tc.status || rec.Header().Get("Content-Type") != tc.contentType && tc.status == http.StatusOK ||
+			!strings.Contains(rec.Body.String(), tc.contains) {
+			t.Fatalf("%s: unexpected response %d %q %q", tc.query, rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+		}
+	}
+
+	if len(h.cache.items) != 4 {
+		t.Fatalf("Unexpected cache size %d", len(h.cache.items))
+	}
+
+	// render exceeding size limit is refused
+	h.maxSize = 10
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render?file=test.cast&format=svg", nil))
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Unexpected response for large render %d %q", rec.Code, rec.Body.String())
+	}
+
+	// cached render is served while all renders are busy, new one waits
+	h.sem <- struct{}{}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render?file=test.cast&format=txt", nil))
+
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Cached render not served: %d", rec.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/render?file=test.cast&format=svg", nil).WithContext(ctx))
+
+	if len(h.cache.items) != 4 || rec.Body.Len() != 0 {
+		t.Fatalf("Render must not start while all renders are busy")
+	}
+
+	// client revalidates render with ETag
+	req := httptest.NewRequest(http.MethodGet, "/render?file=test.cast&format=txt", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("Unexpected revalidation response %d", rec.Code)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const (
+	// maxSearchHits is a maximal number of hits returned for query.
+	maxSearchHits = 1000
+
+	// maxSearchScan is a maximal size of frames data replayed for query.
+	maxSearchScan = 256 << 20
+)
+
+type SearchHit struct {
+	File string  `json:"file"`
+	Time float64 `json:"time"`
+	Line string  `json:"line"`
+}
+
+// SearchHandler finds casts where text from "q" query parameter appeared on screen (case-insensitive).
+// Number of concurrent searches, hits and replayed data are limited, "truncated" is set in response if limit is reached.
+type SearchHandler struct {
+	Library *Library
+
+	sem chan struct{}
+}
+
+func newSearchHandler(lib *Library, concurrency int) *SearchHandler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &SearchHandler{
+		Library: lib,
+		sem:     make(chan struct{}, concurrency),
+	}
+}
+
+func (h *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		http.Error(w, "Query is empty", http.StatusBadRequest)
+		return
+	}
+
+	pattern := regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+
+	ctx := r.Context()
+
+	select {
+	case h.sem <- struct{}{}:
+		defer func() { <-h.sem }()
+	case <-ctx.Done():
+		return
+	}
+
+	var (
+		hits      = []SearchHit{}
+		budget    = maxSearchScan
+		truncated bool
+	)
+
+	for _, fileName := range h.Library.Names() {
+		if budget <= 0 || len(hits) >= maxSearchHits {
+			truncated = true
+			break
+		}
+
+		fileHits, err := h.searchFile(ctx, fileName, pattern, &budget)
+		if ctx.Err() != nil {
+			return // nobody waits for result
+		}
+
+		if err != nil {
+			continue // broken casts are not interesting for search
+		}
+
+		for _, hit := range fileHits {
+			if len(hits) >= maxSearchHits {
+				truncated = true
+				break
+			}
+
+			hits = append(hits, SearchHit{File: fileName, Time: hit.Time, Line: hit.Line})
+		}
+	}
+
+	if budget <= 0 {
+		truncated = true
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"hits": hits, "truncated": truncated})
+}
+
+func (h *SearchHandler) searchFile(ctx context.Context, fileName string, pattern *regexp.Regexp, budget *int) ([]player.SearchHit, error) {
+	file, err := h.Library.fsys.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	src, err := player.NewStreamFrameSource(file, player.WithLenientParsing())
+	if err != nil {
+		return nil, err
+	}
+
+	return player.Search(&searchSource{FrameSource: src, ctx: ctx, budget: budget}, pattern)
+}
+
+// searchSource stops search when request is cancelled with error and when scan budget is exhausted
+// without error, so hits found before are returned.
+type searchSource struct {
+	player.FrameSource
+
+	ctx    context.Context
+	budget *int
+	err    error
+}
+
+func (s *searchSource) Next() bool {
+	if s.err = s.ctx.Err(); s.err != nil {
+		return false
+	}
+
+	if *s.budget <= 0 || !s.FrameSource.Next() {
+		return false
+	}
+
+	*s.budget -= len(s.FrameSource.Frame().Data)
+
+	return true
+}
+
+func (s *searchSource) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	return s.FrameSource.Err()
+}
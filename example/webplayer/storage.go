@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Storage keeps casts. Reading is done through fs.FS so storage may be indexed by Library and played,
+// other methods modify it. Names are slash-separated paths valid for fs.FS.
+//
+// DirStorage keeps casts in local directory, other stores (i.e. S3-compatible) may implement this interface.
+type Storage interface {
+	fs.FS
+
+	// Put creates or replaces cast. Readers must see either old or new content, never partially written one.
+	Put(name string, r io.Reader) error
+
+	// Rename renames cast. It fails with fs.ErrExist if newName is taken.
+	Rename(oldName, newName string) error
+
+	// Remove removes cast.
+	Remove(name string) error
+}
+
+// DirStorage is a Storage keeping casts in local directory.
+type DirStorage struct {
+	fs.FS
+
+	root string
+}
+
+func NewDirStorage(root string) *DirStorage {
+	return &DirStorage{FS: os.DirFS(root), root: root}
+}
+
+// Put writes cast to temporary file and moves it to place.
+func (s *DirStorage) Put(name string, r io.Reader) error {
+	target, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	// temporary file is created with 0600
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), target)
+}
+
+// Rename links cast under new name and removes old one, so existing cast can't be replaced by concurrent rename.
+func (s *DirStorage) Rename(oldName, newName string) error {
+	oldPath, err := s.path(oldName)
+	if err != nil {
+		return err
+	}
+
+	newPath, err := s.path(newName)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(newPath), 0o755); err != nil {
+		return err
+	}
+
+	// link fails if new name is taken
+	if err = os.Link(oldPath, newPath); err != nil {
+		return err
+	}
+
+	return os.Remove(oldPath)
+}
+
+func (s *DirStorage) Remove(name string) error {
+	target, err := s.path(name)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(target)
+}
+
+// path converts name to path in root directory, names escaping it are refused.
+func (s *DirStorage) path(name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(name)), nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestDirStorage_Rename(t *testing.T) {
+	storage := NewDirStorage(t.TempDir())
+
+	for _, name := range []string{"a.cast", "b.cast"} {
+		if err := storage.Put(name, strings.NewReader(name)); err != nil {
+			t.Fatalf("Put failed: %s", err)
+		}
+	}
+
+	if err := storage.Rename("a.cast", "b.cast"); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("Unexpected error for taken name: %v", err)
+	}
+
+	if err := storage.Rename("missing.cast", "c.cast"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Unexpected error for missing cast: %v", err)
+	}
+
+	if err := storage.Rename("a.cast", "dir/c.cast"); err != nil {
+		t.Fatalf("Rename failed: %s", err)
+	}
+
+	if data, err := fs.ReadFile(storage, "dir/c.cast"); err != nil || string(data) != "a.cast" {
+		t.Fatalf("Unexpected renamed cast %q: %v", data, err)
+	}
+
+	if _, err := fs.Stat(storage, "a.cast"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Old cast was not removed: %v", err)
+	}
+}
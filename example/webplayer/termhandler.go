@@ -1,62 +1,133 @@
 package main
 
 import (
-	player "github.com/xakep666/asciinema-player/v3"
-	"io/fs"
+	"fmt"
+	"math"
 	"net/http"
-	"nhooyr.io/websocket"
+	"net/url"
+	"strconv"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/wsterm"
 )
 
-type TermHandler struct {
-	FS      fs.FS
-	FileSet map[string]struct{}
+// newTermHandler returns handler playing casts of library to websocket clients.
+func newTermHandler(lib *Library) *wsterm.Handler {
+	return &wsterm.Handler{
+		Open: func(r *http.Request) (*wsterm.Recording, error) {
+			return openRecording(lib, r)
+		},
+	}
 }
 
-func (h *TermHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	fileName := r.URL.Query().Get("file")
-	if _, ok := h.FileSet[fileName]; !ok {
-		http.Error(w, "Requested file was not found", http.StatusNotFound)
-		return
-	}
+// openRecording opens cast requested by "file" parameter. Playback is set up by deep link parameters:
+// "t" - start position in seconds or as duration (i.e. "1m23.5s"), "marker" - start position at marker
+// given by label or 1-based number (overrides "t"), "speed" - playback speed and "loop" - restart playback
+// from start position when it ends.
+func openRecording(lib *Library, r *http.Request) (*wsterm.Recording, error) {
+	query := r.URL.Query()
 
-	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{})
+	rec, err := openCast(lib, query.Get("file"))
 	if err != nil {
-		return
+		return nil, err
+	}
+
+	if rec.Options, err = playbackOptions(query, rec.Metadata.Markers); err != nil {
+		rec.Close()
+		return nil, err
 	}
 
-	go h.handleConn(fileName, conn)
+	return rec, nil
 }
 
-func (h *TermHandler) handleConn(fileName string, conn *websocket.Conn) {
-	term, err := NewWSTerm(conn)
+// openCast opens cast of library for playback from beginning.
+func openCast(lib *Library, fileName string) (*wsterm.Recording, error) {
+	metadata, err := lib.Metadata(fileName)
 	if err != nil {
-		conn.Close(websocket.StatusProtocolError, "failed to initiate terminal:"+err.Error())
-		return
+		return nil, err
 	}
 
-	defer term.Close()
+	file, err := lib.fsys.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
 
-	file, err := h.FS.Open(fileName)
+	src, err := player.NewStreamFrameSource(file)
 	if err != nil {
-		conn.Close(websocket.StatusProtocolError, "file open failed:"+err.Error())
-		return
+		file.Close()
+		return nil, fmt.Errorf("frame source create failed: %w", err)
 	}
 
-	defer file.Close()
+	return &wsterm.Recording{
+		Source:   src,
+		Close:    file.Close,
+		Metadata: metadata,
+	}, nil
+}
 
-	src, err := player.NewStreamFrameSource(file)
+// playbackOptions returns player options set by deep link parameters.
+func playbackOptions(query url.Values, markers []wsterm.Marker) ([]player.Option, error) {
+	startAt, err := startPosition(query, markers)
 	if err != nil {
-		conn.Close(websocket.StatusProtocolError, "frame source create failed:"+err.Error())
-		return
+		return nil, err
+	}
+
+	opts := []player.Option{player.WithStartAt(startAt)}
+
+	if value := query.Get("speed"); value != "" {
+		speed, err := strconv.ParseFloat(value, 64)
+		if err != nil || speed <= 0 || math.IsInf(speed, 0) {
+			return nil, fmt.Errorf("invalid speed")
+		}
+
+		opts = append(opts, player.WithSpeed(speed))
+	}
+
+	if value := query.Get("loop"); value != "" {
+		loop, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid loop flag")
+		}
+
+		if loop {
+			opts = append(opts, player.WithLoop())
+		}
+	}
+
+	return opts, nil
+}
+
+// startPosition returns playback start position from "marker" or "t" parameter, i.e. position of search hit.
+func startPosition(query url.Values, markers []wsterm.Marker) (float64, error) {
+	if marker := query.Get("marker"); marker != "" {
+		for i, m := range markers {
+			if m.Label == marker || strconv.Itoa(i+1) == marker {
+				return m.Time, nil
+			}
+		}
+
+		return 0, fmt.Errorf("marker %q not found", marker)
 	}
 
-	p, err := player.NewPlayer(src, term)
+	value := query.Get("t")
+	if value == "" {
+		return 0, nil
+	}
+
+	position, err := strconv.ParseFloat(value, 64)
 	if err != nil {
-		conn.Close(websocket.StatusProtocolError, "player create failed:"+err.Error())
-		return
+		var duration time.Duration
+		if duration, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid start position")
+		}
+
+		position = duration.Seconds()
 	}
 
-	if err = p.Start(); err != nil {
-		conn.Close(websocket.StatusProtocolError, "playback error:"+err.Error())
+	if position < 0 || math.IsNaN(position) || math.IsInf(position, 0) {
+		return 0, fmt.Errorf("invalid start position")
 	}
+
+	return position, nil
 }
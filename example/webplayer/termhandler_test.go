@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/xakep666/asciinema-player/v3/wsterm"
+)
+
+func TestStartPosition(t *testing.T) {
+	markers := []wsterm.Marker{{Time: 10, Label: "build"}, {Time: 20, Label: "deploy"}}
+
+	for _, tc := range []struct {
+		query    string
+		position float64
+		valid    bool
+	}{
+		{"", 0, true},
+		{"t=12.5", 12.5, true},
+		{"t=1m23.5s", 83.5, true},
+		{"t=-1", 0, false},
+		{"t=soon", 0, false},
+		{"marker=deploy&t=5", 20, true},
+		{"marker=1", 10, true},
+		{"marker=3", 0, false},
+	} {
+		query, _ := url.ParseQuery(tc.query)
+
+		position, err := startPosition(query, markers)
+		if (err == nil) != tc.valid || position != tc.position {
+			t.Fatalf("%q: unexpected position %f, error %v", tc.query, position, err)
+		}
+	}
+}
+
+func TestOpenCast(t *testing.T) {
+	lib := NewLibrary(fstest.MapFS{
+		"test.cast": {Data: []byte(`{"version":2,"width":20,"height":3,"title":"Demo"}
+[0.5,"o","build\r\n"]
+[1,"m","deploy"]
+[1.5,"o","done\r\n"]
+`)},
+		"broken.cast": {Data: []byte(`not a cast`)},
+	})
+	if err := lib.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %s", err)
+	}
+
+	// metadata is taken from index
+	rec, err := openCast(lib, "test.cast")
+	if err != nil {
+		t.Fatalf("Open failed: %s", err)
+	}
+
+	rec.Close()
+
+	expected := &wsterm.Metadata{Duration: 1.5, Markers: []wsterm.Marker{{Time: 1, Label: "deploy"}}, Title: "Demo"}
+	if !reflect.DeepEqual(expected, rec.Metadata) {
+		t.Fatalf("Unexpected metadata: %+v", rec.Metadata)
+	}
+
+	if _, err = openCast(lib, "broken.cast"); err == nil {
+		t.Fatalf("Broken cast opened")
+	}
+
+	if _, err = openCast(lib, "missing.cast"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Unexpected error for missing cast: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package player
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// ExportText plays recording into virtual terminal and writes whole scrollback followed by final screen as plain text.
+// Lines wrapped because of screen width are joined.
+func ExportText(w io.Writer, src FrameSource) error {
+	var lines []vt.Line
+
+	screen, err := playToScreen(src, nil, vt.WithScrollback(0), vt.WithScrollHandler(func(line vt.Line) {
+		lines = append(lines, line)
+	}))
+	if err != nil {
+		return err
+	}
+
+	text := vt.JoinWrapped(append(lines, screen.Lines()...))
+	for len(text) > 0 && text[len(text)-1] == "" {
+		text = text[:len(text)-1]
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, line := range text {
+		bw.WriteString(line)
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// playToScreen writes output and resize frames to virtual terminal. Function afterFrame (if not nil) is called after every frame.
+func playToScreen(src FrameSource, afterFrame func(*vt.Screen), opts ...vt.Option) (*vt.Screen, error) {
+	hdr := src.Header()
+	screen := vt.New(hdr.Width, hdr.Height, opts...)
+
+	for src.Next() {
+		frame := src.Frame()
+
+		switch frame.Type {
+		case OutputFrame:
+			screen.Write(frame.Data)
+		case ResizeFrame:
+			if width, height, err := ParseResizeData(frame.Data); err == nil {
+				screen.Resize(width, height)
+			}
+		default:
+			continue
+		}
+
+		if afterFrame != nil {
+			afterFrame(screen)
+		}
+	}
+
+	return screen, src.Err()
+}
+
+// ExportTranscript plays recording into virtual terminal and writes every line of main screen once
+// prefixed with time when it appeared, i.e. "[01:23.456] $ make". Line is written when it's finished:
+// scrolled out of screen, erased or at the end of recording. So progress bars redrawn with carriage return
+// give single line with final state. Full-screen applications working in alternate screen are not included.
+func ExportTranscript(w io.Writer, src FrameSource) error {
+	t := &transcript{w: bufio.NewWriter(w)}
+
+	// remember time of frame being written: lines may scroll out during write
+	src = Map(src, func(frame Frame) Frame {
+		t.time = frame.Time
+		return frame
+	})
+
+	screen, err := playToScreen(src, t.update, vt.WithScrollback(0), vt.WithScrollHandler(t.scrolled))
+	if err != nil {
+		return err
+	}
+
+	if !screen.AltScreen() {
+		t.update(screen)
+	}
+
+	// trailing empty rows are not a part of transcript
+	rows := t.rows
+	for len(rows) > 0 && rows[len(rows)-1].text == "" {
+		rows = rows[:len(rows)-1]
+	}
+
+	for _, row := range rows {
+		t.emit(row)
+	}
+
+	if t.hasWrapped {
+		t.flushWrapped()
+	}
+
+	return t.w.Flush()
+}
+
+type transcriptRow struct {
+	text      string
+	wrapped   bool
+	firstSeen float64
+}
+
+type transcript struct {
+	w    *bufio.Writer
+	rows []transcriptRow // last seen state of main screen
+	time float64
+
+	// text of wrapped line parts waiting for the rest
+	wrappedText strings.Builder
+	wrappedTime float64
+	hasWrapped  bool
+}
+
+// scrolled finishes line scrolled out of screen. It's called during write, before update.
+func (t *transcript) scrolled(line vt.Line) {
+	row := transcriptRow{text: rowText(line), wrapped: line.Wrapped, firstSeen: t.time}
+	if len(t.rows) > 0 {
+		if t.rows[0].text != "" {
+			row.firstSeen = t.rows[0].firstSeen
+		}
+
+		t.rows = t.rows[1:]
+	}
+
+	t.emit(row)
+}
+
+// update compares main screen with last seen state, finishes erased lines and remembers when lines appear.
+func (t *transcript) update(screen *vt.Screen) {
+	if screen.AltScreen() {
+		return
+	}
+
+	lines := screen.Lines()
+	rows := make([]transcriptRow, len(lines))
+
+	for y, line := range lines {
+		row := transcriptRow{text: rowText(line), wrapped: line.Wrapped, firstSeen: t.time}
+
+		if y < len(t.rows) && t.rows[y].text != "" {
+			if row.text == "" {
+				t.emit(t.rows[y]) // erased
+			} else {
+				row.firstSeen = t.rows[y].firstSeen
+			}
+		}
+
+		rows[y] = row
+	}
+
+	t.rows = rows
+}
+
+func (t *transcript) emit(row transcriptRow) {
+	if !t.hasWrapped {
+		t.wrappedTime = row.firstSeen
+	}
+
+	if row.wrapped {
+		t.wrappedText.WriteString(row.text)
+		t.hasWrapped = true
+
+		return
+	}
+
+	t.wrappedText.WriteString(row.text)
+	t.flushWrapped()
+}
+
+// rowText returns text of line. Trailing spaces are kept for wrapped line because text continues on next row.
+func rowText(line vt.Line) string {
+	if !line.Wrapped {
+		return line.String()
+	}
+
+	var sb strings.Builder
+	for _, cell := range line.Cells {
+		if cell.Width > 0 {
+			sb.WriteRune(cell.Rune)
+		}
+	}
+
+	return sb.String()
+}
+
+func (t *transcript) flushWrapped() {
+	text := strings.TrimRight(t.wrappedText.String(), " ")
+	if text == "" {
+		t.w.WriteByte('\n')
+	} else {
+		fmt.Fprintf(t.w, "[%s] %s\n", formatTimestamp(t.wrappedTime), text)
+	}
+
+	t.wrappedText.Reset()
+	t.hasWrapped = false
+}
+
+// formatTimestamp formats time in seconds as "mm:ss.mmm" or "hh:mm:ss.mmm".
+func formatTimestamp(seconds float64) string {
+	ms := int64(seconds*1000 + 0.5)
+	h, m, s, ms := ms/3600000, ms/60000%60, ms/1000%60, ms%1000
+
+	if h > 0 {
+		return fmt.Sprintf("%02d:%02d:%02d.%03d", h, m, s, ms)
+	}
+
+	return fmt.Sprintf("%02d:%02d.%03d", m, s, ms)
+}
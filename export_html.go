@@ -0,0 +1,63 @@
+package player
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+)
+
+//go:embed export_html.tmpl
+var htmlPlayerTemplate string
+
+var htmlPlayer = template.Must(template.New("player").Parse(htmlPlayerTemplate))
+
+// htmlCast is a recording embedded into HTML page.
+type htmlCast struct {
+	Header  Header  `json:"header"`
+	Frames  []Frame `json:"frames"`
+	Speed   float64 `json:"speed"`
+	MaxWait float64 `json:"maxWait"`
+	StartAt float64 `json:"startAt"`
+}
+
+// ExportHTML writes single self-contained HTML page with recording and built-in terminal player,
+// so it can be opened in browser offline. Title and theme are taken from header.
+// Speed, maximal wait and start position options are applied like in Player.
+// Input frames are not included.
+func ExportHTML(w io.Writer, src FrameSource, opts ...Option) error {
+	options := options{speed: 1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	cast := htmlCast{
+		Header:  src.Header(),
+		Speed:   options.speed,
+		MaxWait: options.maxWait.Seconds(),
+		StartAt: options.startAt,
+	}
+
+	for src.Next() {
+		frame := src.Frame()
+		if frame.Type == InputFrame {
+			continue
+		}
+
+		frame.Data = append([]byte(nil), frame.Data...)
+		cast.Frames = append(cast.Frames, frame)
+	}
+
+	if err := src.Err(); err != nil {
+		return err
+	}
+
+	title := cast.Header.Title
+	if title == "" {
+		title = "asciinema recording"
+	}
+
+	return htmlPlayer.Execute(w, struct {
+		Title string
+		Cast  htmlCast
+	}{Title: title, Cast: cast})
+}
@@ -0,0 +1,95 @@
+package player_test
+
+import (
+	"strings"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestExportText(t *testing.T) {
+	src := mustSourceFromString(t, `{"version":2,"width":10,"height":3}
+[1,"o","$ ls\r\n"]
+[2,"o","a.txt b.txt c.txt\r\n"]
+[3,"o","$ top\r\n"]
+[4,"o","\u001b[?1049h\u001b[Hload 1.00"]
+[5,"o","\u001b[?1049l$ "]
+`)
+
+	var sb strings.Builder
+
+	if err := player.ExportText(&sb, src); err != nil {
+		t.Fatalf("ExportText failed: %s", err)
+	}
+
+	expected := "$ ls\na.txt b.txt c.txt\n$ top\n$\n"
+	if sb.String() != expected {
+		t.Fatalf("Unexpected text: %q", sb.String())
+	}
+}
+
+func TestExportTranscript(t *testing.T) {
+	src := mustSourceFromString(t, `{"version":2,"width":10,"height":3}
+[1,"o","$ make\r\n"]
+[2,"o","10%"]
+[3,"o","\r50%"]
+[4,"o","\r100%\r\n\r\n"]
+[5,"o","$ vim\r\n"]
+[6,"o","\u001b[?1049h\u001b[Hediting"]
+[7,"o","\u001b[?1049l$ clear"]
+[8,"o","\r\n\u001b[H\u001b[2J$ echo 0123456789ab\r\n"]
+[62,"o","0123456789ab\r\n$ "]
+`)
+
+	var sb strings.Builder
+
+	if err := player.ExportTranscript(&sb, src); err != nil {
+		t.Fatalf("ExportTranscript failed: %s", err)
+	}
+
+	expected := `[00:01.000] $ make
+[00:02.000] 100%
+
+[00:05.000] $ vim
+[00:07.000] $ clear
+[00:08.000] $ echo 0123456789ab
+[01:02.000] 0123456789ab
+[01:02.000] $
+`
+	if sb.String() != expected {
+		t.Fatalf("Unexpected transcript:\n%s", sb.String())
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	src := mustSourceFromString(t, `{"version":2,"width":10,"height":3,"title":"<demo>","theme":{"fg":"#ffffff","bg":"#000000","palette":"#111111:#222222:#333333:#444444:#555555:#666666:#777777:#888888"}}
+[1,"o","</script>hello"]
+[2,"i","secret"]
+[3,"r","20x5"]
+`)
+
+	var sb strings.Builder
+
+	if err := player.ExportHTML(&sb, src, player.WithSpeed(2), player.WithStartAt(1)); err != nil {
+		t.Fatalf("ExportHTML failed: %s", err)
+	}
+
+	page := sb.String()
+
+	for _, expected := range []string{
+		`<title>&lt;demo&gt;</title>`,
+		`"palette":"#111111:#222222`,
+		`[1,"o","\u003c/script\u003ehello"]`,
+		`[3,"r","20x5"]`,
+		`"speed":2`,
+		`"startAt":1`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Fatalf("Page doesn't contain %s", expected)
+		}
+	}
+
+	if strings.Contains(page, "secret") || strings.Contains(page, "</script>hello") {
+		t.Fatalf("Page contains input or unescaped output")
+	}
+}
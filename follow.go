@@ -0,0 +1,69 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultFollowInterval is a default poll interval for FollowReader.
+const DefaultFollowInterval = 100 * time.Millisecond
+
+// FollowReader reads from io.Reader like "tail -f": when underlying reader reaches io.EOF it waits for new data instead.
+// It's useful to play recordings that are still being written.
+type FollowReader struct {
+	reader   io.Reader
+	interval time.Duration
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewFollowReader constructs FollowReader. Underlying reader polled with given interval after io.EOF.
+// Non-positive interval replaced with DefaultFollowInterval.
+func NewFollowReader(reader io.Reader, interval time.Duration) *FollowReader {
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+
+	return &FollowReader{
+		reader:   reader,
+		interval: interval,
+		closed:   make(chan struct{}),
+	}
+}
+
+// Read reads data from underlying reader. It blocks until data appears or Close called. After Close it returns io.EOF.
+func (r *FollowReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closed:
+			return 0, io.EOF
+		default:
+		}
+
+		n, err = r.reader.Read(p)
+		if n > 0 || (err != nil && err != io.EOF) {
+			return n, err
+		}
+
+		select {
+		case <-r.closed:
+			return 0, io.EOF
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close interrupts pending Read. It doesn't close underlying reader.
+func (r *FollowReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
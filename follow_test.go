@@ -0,0 +1,106 @@
+package player_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestFollowReader(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "live.cast"))
+	if err != nil {
+		t.Fatalf("File create failed: %s", err)
+	}
+
+	defer file.Close()
+
+	reader, err := os.Open(file.Name())
+	if err != nil {
+		t.Fatalf("File open failed: %s", err)
+	}
+
+	defer reader.Close()
+
+	if _, err = file.WriteString(`{"version":2,"width":80,"height":24}` + "\n" + `[0.1,"o","a"]` + "\n"); err != nil {
+		t.Fatalf("File write failed: %s", err)
+	}
+
+	followReader := player.NewFollowReader(reader, 10*time.Millisecond)
+
+	source, err := player.NewStreamFrameSource(followReader)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if !source.Next() || string(source.Frame().Data) != "a" {
+		t.Fatalf("Expected first frame, got error: %v", source.Err())
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		file.WriteString(`[0.2,"o",`) // partially written line
+		time.Sleep(50 * time.Millisecond)
+		file.WriteString(`"b"]` + "\n")
+	}()
+
+	if !source.Next() || string(source.Frame().Data) != "b" {
+		t.Fatalf("Expected appended frame, got error: %v", source.Err())
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		followReader.Close()
+	}()
+
+	if source.Next() {
+		t.Fatalf("Unexpected frame after close")
+	}
+
+	if err = source.Err(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if n, err := followReader.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Fatalf("Read after close must return io.EOF, got %d, %v", n, err)
+	}
+}
+
+type followFrameSource struct {
+	*player.StreamFrameSource
+	*player.FollowReader
+}
+
+func TestPlayer_StopFollowing(t *testing.T) {
+	reader := strings.NewReader(`{"version":2,"width":80,"height":24}` + "\n" + `[0.1,"o","a"]` + "\n")
+	followReader := player.NewFollowReader(reader, 10*time.Millisecond)
+
+	source, err := player.NewStreamFrameSource(followReader)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	term := &bufferTerminal{Width: 100, Height: 100}
+
+	p, err := player.NewPlayer(&followFrameSource{StreamFrameSource: source, FollowReader: followReader}, term)
+	if err != nil {
+		t.Fatalf("Player setup failed: %s", err)
+	}
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		p.Stop()
+	}()
+
+	if err = p.Start(); err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+
+	if term.String() != "a" {
+		t.Fatalf("Unexpected terminal output: %q", term.String())
+	}
+}
@@ -3,6 +3,9 @@ package player
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 )
 
 // Frame represents asciinema-v2 frame.
@@ -31,6 +34,11 @@ func (e *FrameUnmarshalError) Error() string {
 	return fmt.Sprintf("frame[%d]: %s", e.Index, e.Description)
 }
 
+// MarshalJSON implements json.Marshaler. Time is rounded to microseconds like asciinema does.
+func (f Frame) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]interface{}{math.Round(f.Time*1e6) / 1e6, f.Type, string(f.Data)})
+}
+
 // UnmarshalJSON implements json.Unmarshaler.
 func (f *Frame) UnmarshalJSON(b []byte) error {
 	var rawFrame [3]interface{}
@@ -48,7 +56,7 @@ func (f *Frame) UnmarshalJSON(b []byte) error {
 	switch frameTypeRaw := rawFrame[1].(type) {
 	case string:
 		switch FrameType(frameTypeRaw) {
-		case InputFrame, OutputFrame:
+		case InputFrame, OutputFrame, ResizeFrame, MarkerFrame:
 			f.Type = FrameType(frameTypeRaw)
 		default:
 			return &FrameUnmarshalError{Description: fmt.Sprintf("invalid value %v", frameTypeRaw), Index: 1}
@@ -66,3 +74,26 @@ func (f *Frame) UnmarshalJSON(b []byte) error {
 
 	return nil
 }
+
+// ResizeData formats data of ResizeFrame.
+func ResizeData(width, height int) []byte {
+	return []byte(strconv.Itoa(width) + "x" + strconv.Itoa(height))
+}
+
+// ParseResizeData parses data of ResizeFrame.
+func ParseResizeData(data []byte) (width, height int, err error) {
+	parts := strings.SplitN(string(data), "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid resize data %q", data)
+	}
+
+	if width, err = strconv.Atoi(parts[0]); err != nil || width <= 0 {
+		return 0, 0, fmt.Errorf("invalid resize width %q", parts[0])
+	}
+
+	if height, err = strconv.Atoi(parts[1]); err != nil || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid resize height %q", parts[1])
+	}
+
+	return width, height, nil
+}
@@ -1,5 +1,9 @@
 package player
 
+import "fmt"
+
+var ErrNotRewindable = fmt.Errorf("frame source can't be rewound")
+
 // FrameType is a type of Frame.
 type FrameType string
 
@@ -9,6 +13,12 @@ const (
 
 	// OutputFrame contains data written to stdout of recorded shell.
 	OutputFrame FrameType = "o"
+
+	// ResizeFrame contains new terminal size in "{width}x{height}" format (i.e. "80x24").
+	ResizeFrame FrameType = "r"
+
+	// MarkerFrame contains marker label. Markers are used as chapters and breakpoints.
+	MarkerFrame FrameType = "m"
 )
 
 const FormatVersion = 2
@@ -23,6 +33,30 @@ type Header struct {
 
 	// Height is a captured terminal height.
 	Height int `json:"height"`
+
+	// Timestamp is a unix timestamp of recording start. Zero if unknown.
+	Timestamp int64 `json:"timestamp,omitempty"`
+
+	// IdleTimeLimit is a maximal pause in seconds recommended for playback. Zero if not set.
+	IdleTimeLimit float64 `json:"idle_time_limit,omitempty"`
+
+	// Title of recording. Empty if not set.
+	Title string `json:"title,omitempty"`
+
+	// Theme is a color theme of recorded terminal. Nil if not set.
+	Theme *Theme `json:"theme,omitempty"`
+}
+
+// Theme is a terminal color theme from asciinema-v2 header.
+type Theme struct {
+	// FG is a default foreground color in "#rrggbb" format.
+	FG string `json:"fg"`
+
+	// BG is a default background color in "#rrggbb" format.
+	BG string `json:"bg"`
+
+	// Palette is a list of 8 or 16 colors in "#rrggbb" format separated by colons.
+	Palette string `json:"palette"`
 }
 
 // FrameSource describes frames source.
@@ -39,3 +73,12 @@ type FrameSource interface {
 	// Err returns error if it happens during iteration.
 	Err() error
 }
+
+// Rewinder is implemented by FrameSource that can be read again from first frame, i.e. from file.
+type Rewinder interface {
+	// CanRewind returns true if Rewind is supported.
+	CanRewind() bool
+
+	// Rewind restarts reading from first frame. ErrNotRewindable is returned if it's not supported.
+	Rewind() error
+}
@@ -0,0 +1,333 @@
+package player
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const scriptHeaderPrefix = "Script started on "
+
+var (
+	scriptColumnsRegexp = regexp.MustCompile(`COLUMNS="(\d+)"`)
+	scriptLinesRegexp   = regexp.MustCompile(`LINES="(\d+)"`)
+
+	scriptStartTimeLayouts = []string{
+		"2006-01-02 15:04:05-07:00",
+		"2006-01-02 15:04:05-0700",
+		time.RFC3339,
+	}
+)
+
+// ScriptFrameSource reads frames recorded by util-linux "script" with timing file (like "scriptreplay" does).
+// Both classic ("script -t") and advanced ("script --log-timing") timing formats are supported.
+// Output entries become OutputFrame, input entries become InputFrame, SIGWINCH entries become ResizeFrame.
+type ScriptFrameSource struct {
+	timing *bufio.Reader
+	output *bufio.Reader
+	input  *bufio.Reader
+
+	hdr        Header
+	time       float64
+	pendingOut []byte // incomplete UTF-8 characters
+	pendingIn  []byte
+
+	nextEntry *scriptTimingEntry
+	frame     Frame
+	line      int
+	err       error
+}
+
+type scriptTimingEntry struct {
+	kind  byte // 'O', 'I', 'S' or 'H'
+	delay float64
+	size  int
+	args  []string // signal name and params or header name and value
+}
+
+// NewScriptFrameSource constructs ScriptFrameSource from timing log and typescript (output log).
+// Input log is used for input entries if it was written to separate file ("--log-in"), otherwise it may be nil.
+// Terminal size is taken from recording header if width or height is zero, it stays zero if header doesn't contain it.
+func NewScriptFrameSource(timing, typescript, input io.Reader, width, height int) (*ScriptFrameSource, error) {
+	s := &ScriptFrameSource{
+		timing: bufio.NewReader(timing),
+		output: bufio.NewReader(typescript),
+		hdr: Header{
+			Version: FormatVersion,
+		},
+	}
+
+	if input != nil {
+		s.input = bufio.NewReader(input)
+		if err := s.skipTypescriptHeader(s.input); err != nil {
+			return nil, fmt.Errorf("read input log header failed: %w", err)
+		}
+	} else {
+		s.input = s.output
+	}
+
+	if err := s.skipTypescriptHeader(s.output); err != nil {
+		return nil, fmt.Errorf("read typescript header failed: %w", err)
+	}
+
+	if err := s.readTimingHeader(); err != nil {
+		return nil, err
+	}
+
+	if width > 0 {
+		s.hdr.Width = width
+	}
+
+	if height > 0 {
+		s.hdr.Height = height
+	}
+
+	return s, nil
+}
+
+// skipTypescriptHeader skips "Script started on ..." line. Newer versions of script store terminal size there.
+func (s *ScriptFrameSource) skipTypescriptHeader(r *bufio.Reader) error {
+	prefix, err := r.Peek(len(scriptHeaderPrefix))
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if string(prefix) != scriptHeaderPrefix {
+		return nil
+	}
+
+	line, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	if m := scriptColumnsRegexp.FindStringSubmatch(line); m != nil {
+		s.hdr.Width, _ = strconv.Atoi(m[1])
+	}
+
+	if m := scriptLinesRegexp.FindStringSubmatch(line); m != nil {
+		s.hdr.Height, _ = strconv.Atoi(m[1])
+	}
+
+	return nil
+}
+
+// readTimingHeader reads header entries of advanced timing log, first non-header entry is kept for Next.
+func (s *ScriptFrameSource) readTimingHeader() error {
+	for {
+		entry, err := s.readTimingEntry()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if entry.kind != 'H' {
+			s.nextEntry = entry
+			return nil
+		}
+
+		s.time += entry.delay
+
+		if len(entry.args) < 2 {
+			continue
+		}
+
+		value := strings.Join(entry.args[1:], " ")
+
+		switch entry.args[0] {
+		case "COLUMNS":
+			s.hdr.Width, _ = strconv.Atoi(value)
+		case "LINES":
+			s.hdr.Height, _ = strconv.Atoi(value)
+		case "START_TIME":
+			for _, layout := range scriptStartTimeLayouts {
+				if t, err := time.Parse(layout, value); err == nil {
+					s.hdr.Timestamp = t.Unix()
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s *ScriptFrameSource) readTimingEntry() (*scriptTimingEntry, error) {
+	for {
+		line, err := s.timing.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return nil, err
+		}
+
+		s.line++
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		entry, parseErr := parseScriptTimingEntry(fields)
+		if parseErr != nil {
+			return nil, fmt.Errorf("timing line %d: %w", s.line, parseErr)
+		}
+
+		return entry, nil
+	}
+}
+
+func parseScriptTimingEntry(fields []string) (*scriptTimingEntry, error) {
+	entry := &scriptTimingEntry{kind: 'O'}
+
+	// classic format is "<delay> <size>", advanced is "<type> <delay> <size or args...>"
+	if first := fields[0]; len(first) == 1 && strings.Contains("OISH", first) {
+		entry.kind = first[0]
+		fields = fields[1:]
+	}
+
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("too few fields")
+	}
+
+	delay, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || delay < 0 {
+		return nil, fmt.Errorf("invalid delay %q", fields[0])
+	}
+
+	entry.delay = delay
+
+	switch entry.kind {
+	case 'O', 'I':
+		if entry.size, err = strconv.Atoi(fields[1]); err != nil || entry.size < 0 {
+			return nil, fmt.Errorf("invalid size %q", fields[1])
+		}
+	default:
+		entry.args = fields[1:]
+	}
+
+	return entry, nil
+}
+
+// Header returns asciinema-v2 header.
+func (s *ScriptFrameSource) Header() Header { return s.hdr }
+
+// Next advances to next available frame. It must return false if error occurs or there is no more frames.
+func (s *ScriptFrameSource) Next() bool {
+	for {
+		entry := s.nextEntry
+		s.nextEntry = nil
+
+		if entry == nil {
+			var err error
+
+			entry, err = s.readTimingEntry()
+			if errors.Is(err, io.EOF) {
+				return s.flushPending()
+			}
+
+			if err != nil {
+				s.err = err
+				return false
+			}
+		}
+
+		s.time += entry.delay
+
+		switch entry.kind {
+		case 'O':
+			if s.readData(s.output, entry.size, OutputFrame, &s.pendingOut) {
+				return true
+			}
+		case 'I':
+			if s.readData(s.input, entry.size, InputFrame, &s.pendingIn) {
+				return true
+			}
+		case 'S':
+			if s.resize(entry.args) {
+				return true
+			}
+		}
+
+		if s.err != nil {
+			return false
+		}
+	}
+}
+
+func (s *ScriptFrameSource) readData(r *bufio.Reader, size int, frameType FrameType, pending *[]byte) bool {
+	data := make([]byte, len(*pending)+size)
+	copy(data, *pending)
+
+	if _, err := io.ReadFull(r, data[len(*pending):]); err != nil {
+		s.err = fmt.Errorf("timing line %d: read %d bytes of data failed: %w", s.line, size, err)
+		return false
+	}
+
+	data, rest := splitIncompleteUTF8(data)
+	*pending = append([]byte(nil), rest...)
+
+	if len(data) == 0 {
+		return false
+	}
+
+	s.frame = Frame{Time: s.time, Type: frameType, Data: data}
+
+	return true
+}
+
+// resize converts "SIGWINCH ROWS=24 COLS=80" signal entry to ResizeFrame.
+func (s *ScriptFrameSource) resize(args []string) bool {
+	if len(args) == 0 || args[0] != "SIGWINCH" {
+		return false
+	}
+
+	var width, height int
+	for _, arg := range args[1:] {
+		if v := strings.TrimPrefix(arg, "COLS="); v != arg {
+			width, _ = strconv.Atoi(v)
+		}
+
+		if v := strings.TrimPrefix(arg, "ROWS="); v != arg {
+			height, _ = strconv.Atoi(v)
+		}
+	}
+
+	if width <= 0 || height <= 0 {
+		return false
+	}
+
+	s.frame = Frame{Time: s.time, Type: ResizeFrame, Data: ResizeData(width, height)}
+
+	return true
+}
+
+// flushPending returns broken characters at the end of streams as is.
+func (s *ScriptFrameSource) flushPending() bool {
+	switch {
+	case len(s.pendingOut) > 0:
+		s.frame = Frame{Time: s.time, Type: OutputFrame, Data: s.pendingOut}
+		s.pendingOut = nil
+	case len(s.pendingIn) > 0:
+		s.frame = Frame{Time: s.time, Type: InputFrame, Data: s.pendingIn}
+		s.pendingIn = nil
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Frame returns current frame. It becomes unusable after Next call.
+func (s *ScriptFrameSource) Frame() Frame { return s.frame }
+
+// Err returns error if it happens during iteration.
+func (s *ScriptFrameSource) Err() error { return s.err }
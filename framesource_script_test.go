@@ -0,0 +1,116 @@
+package player_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestScriptFrameSource_Classic(t *testing.T) {
+	typescript := "Script started on 2022-01-02 10:00:00+00:00 [TERM=\"xterm\" TTY=\"/dev/pts/1\" COLUMNS=\"120\" LINES=\"30\"]\n" +
+		"h\xc3\xa9llo\r\nworld\r\n\nScript done on 2022-01-02 10:00:01+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+	timing := "0.5 2\n0.25 6\n1 7\n"
+
+	src, err := player.NewScriptFrameSource(strings.NewReader(timing), strings.NewReader(typescript), nil, 0, 0)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if hdr := src.Header(); hdr.Width != 120 || hdr.Height != 30 {
+		t.Fatalf("Unexpected header: %+v", hdr)
+	}
+
+	expected := []player.Frame{
+		{Time: 0.5, Type: player.OutputFrame, Data: []byte("h")}, // split character moved to next frame
+		{Time: 0.75, Type: player.OutputFrame, Data: []byte("éllo\r\n")},
+		{Time: 1.75, Type: player.OutputFrame, Data: []byte("world\r\n")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestScriptFrameSource_Advanced(t *testing.T) {
+	timing := `H 0.000000 START_TIME 2022-01-02 10:00:00+00:00
+H 0.000000 COLUMNS 80
+H 0.000000 LINES 24
+O 0.5 2
+I 0.25 1
+O 0 1
+S 1 SIGWINCH ROWS=40 COLS=100
+S 0 SIGTERM
+O 0.5 3
+H 0.000000 DURATION 2.25
+`
+
+	t.Run("log-io", func(t *testing.T) {
+		typescript := "Script started on 2022-01-02 10:00:00+00:00 [COMMAND=\"bash\"]\n$ llls\n"
+
+		src, err := player.NewScriptFrameSource(strings.NewReader(timing), strings.NewReader(typescript), nil, 0, 0)
+		if err != nil {
+			t.Fatalf("Source create failed: %s", err)
+		}
+
+		if hdr := src.Header(); hdr.Width != 80 || hdr.Height != 24 || hdr.Timestamp != 1641117600 {
+			t.Fatalf("Unexpected header: %+v", hdr)
+		}
+
+		expected := []player.Frame{
+			{Time: 0.5, Type: player.OutputFrame, Data: []byte("$ ")},
+			{Time: 0.75, Type: player.InputFrame, Data: []byte("l")},
+			{Time: 0.75, Type: player.OutputFrame, Data: []byte("l")},
+			{Time: 1.75, Type: player.ResizeFrame, Data: []byte("100x40")},
+			{Time: 2.25, Type: player.OutputFrame, Data: []byte("ls\n")},
+		}
+
+		if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Unexpected frames: %+v", actual)
+		}
+	})
+
+	t.Run("separate input", func(t *testing.T) {
+		output := "$ lls\n"
+		input := "Script started on 2022-01-02 10:00:00+00:00 [COMMAND=\"bash\"]\nl"
+
+		src, err := player.NewScriptFrameSource(strings.NewReader(timing), strings.NewReader(output), strings.NewReader(input), 132, 0)
+		if err != nil {
+			t.Fatalf("Source create failed: %s", err)
+		}
+
+		if hdr := src.Header(); hdr.Width != 132 || hdr.Height != 24 {
+			t.Fatalf("Unexpected header: %+v", hdr)
+		}
+
+		frames := readFrames(t, src)
+		if len(frames) != 5 || string(frames[1].Data) != "l" || string(frames[4].Data) != "ls\n" {
+			t.Fatalf("Unexpected frames: %+v", frames)
+		}
+	})
+}
+
+func TestScriptFrameSource_Errors(t *testing.T) {
+	src, err := player.NewScriptFrameSource(strings.NewReader("0.5 10\n"), strings.NewReader("short"), nil, 80, 24)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if src.Next() || src.Err() == nil {
+		t.Fatalf("Error expected for short typescript")
+	}
+
+	src, err = player.NewScriptFrameSource(strings.NewReader("0.5 1\nbroken\n"), strings.NewReader("ab"), nil, 80, 24)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if !src.Next() {
+		t.Fatalf("First frame expected")
+	}
+
+	if src.Next() || !strings.Contains(src.Err().Error(), "timing line 2") {
+		t.Fatalf("Timing line error expected, got %v", src.Err())
+	}
+}
@@ -1,33 +1,144 @@
 package player
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 )
 
-// StreamFrameSource reads frames from io.Reader.
+type streamOptions struct {
+	lenient bool
+}
+
+// StreamOption for StreamFrameSource.
+type StreamOption func(*streamOptions)
+
+// WithLenientParsing makes StreamFrameSource skip malformed frames instead of stopping with error.
+// Skipped lines are available through StreamFrameSource.Skipped. Truncated last line written by crashed recorder is skipped too.
+// Frames must be placed on separate lines in this mode.
+func WithLenientParsing() StreamOption {
+	return func(o *streamOptions) {
+		o.lenient = true
+	}
+}
+
+// LineError describes malformed line of input.
+type LineError struct {
+	// Line is a 1-based line number.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string { return fmt.Sprintf("line %d: %s", e.Line, e.Err) }
+
+func (e *LineError) Unwrap() error { return e.Err }
+
+// StreamFrameSource reads frames from io.Reader. It may be rewound if reader implements io.Seeker.
 type StreamFrameSource struct {
 	dec *json.Decoder
 
+	// lines and line used in lenient mode
+	lines   *bufio.Reader
+	line    int
+	skipped []*LineError
+
+	reader  io.Reader
+	seeker  io.Seeker // nil if reader can't seek
+	start   int64     // offset of header
+	lenient bool
+
 	hdr   Header
 	frame Frame
 	err   error
 }
 
 // NewStreamFrameSource constructs StreamFrameSource. It reads Header from input stream.
-func NewStreamFrameSource(reader io.Reader) (*StreamFrameSource, error) {
-	dec := json.NewDecoder(reader)
+func NewStreamFrameSource(reader io.Reader, opts ...StreamOption) (*StreamFrameSource, error) {
+	var o streamOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &StreamFrameSource{reader: reader, lenient: o.lenient}
+
+	// pipes and terminals are seekers failing to seek
+	if seeker, ok := reader.(io.Seeker); ok {
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			s.seeker, s.start = seeker, start
+		}
+	}
+
+	if err := s.readHeader(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *StreamFrameSource) readHeader() error {
+	if s.lenient {
+		s.lines, s.line = bufio.NewReader(s.reader), 0
+
+		line, err := s.readLine()
+		if err != nil {
+			return fmt.Errorf("read header failed: %w", err)
+		}
+
+		if err = json.Unmarshal(line, &s.hdr); err != nil {
+			return fmt.Errorf("read header failed: %w", &LineError{Line: s.line, Err: err})
+		}
+
+		return nil
+	}
 
-	var hdr Header
-	if err := dec.Decode(&hdr); err != nil {
-		return nil, fmt.Errorf("read header failed: %w", err)
+	s.dec = json.NewDecoder(s.reader)
+
+	if err := s.dec.Decode(&s.hdr); err != nil {
+		return fmt.Errorf("read header failed: %w", err)
 	}
 
-	return &StreamFrameSource{
-		dec: dec,
-		hdr: hdr,
-	}, nil
+	return nil
+}
+
+// CanRewind returns true if reader of source implements io.Seeker and supports seeking.
+func (s *StreamFrameSource) CanRewind() bool { return s.seeker != nil }
+
+// Rewind seeks reader back to header, so frames are read again from first one.
+func (s *StreamFrameSource) Rewind() error {
+	if s.seeker == nil {
+		return ErrNotRewindable
+	}
+
+	if _, err := s.seeker.Seek(s.start, io.SeekStart); err != nil {
+		return fmt.Errorf("seek failed: %w", err)
+	}
+
+	s.hdr, s.frame, s.err, s.skipped = Header{}, Frame{}, nil, nil
+
+	return s.readHeader()
+}
+
+// readLine returns next non-empty line.
+func (s *StreamFrameSource) readLine() ([]byte, error) {
+	for {
+		line, err := s.lines.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
+		}
+
+		s.line++
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
 }
 
 // Header returns asciinema-v2 header.
@@ -35,6 +146,10 @@ func (s *StreamFrameSource) Header() Header { return s.hdr }
 
 // Next advances to next available frame. It must return false if error occurs or there is no more frames.
 func (s *StreamFrameSource) Next() bool {
+	if s.lines != nil {
+		return s.nextLenient()
+	}
+
 	err := s.dec.Decode(&s.frame)
 	switch err {
 	case nil:
@@ -47,8 +162,35 @@ func (s *StreamFrameSource) Next() bool {
 	}
 }
 
+func (s *StreamFrameSource) nextLenient() bool {
+	for {
+		line, err := s.readLine()
+		switch {
+		case errors.Is(err, nil):
+		case errors.Is(err, io.EOF):
+			return false
+		default:
+			s.err = err
+			return false
+		}
+
+		var frame Frame
+		if err = json.Unmarshal(line, &frame); err != nil {
+			s.skipped = append(s.skipped, &LineError{Line: s.line, Err: err})
+			continue
+		}
+
+		s.frame = frame
+
+		return true
+	}
+}
+
 // Frame returns current frame. It becomes unusable after Next call.
 func (s *StreamFrameSource) Frame() Frame { return s.frame }
 
 // Err returns error if it happens during iteration.
 func (s *StreamFrameSource) Err() error { return s.err }
+
+// Skipped returns malformed lines skipped so far in lenient mode.
+func (s *StreamFrameSource) Skipped() []*LineError { return s.skipped }
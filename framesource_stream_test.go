@@ -3,8 +3,12 @@ package player_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	player "github.com/xakep666/asciinema-player/v3"
@@ -43,3 +47,99 @@ func TestStreamFrameSource(t *testing.T) {
 		t.Fatalf("Output not equal to input. Output:\n%s", buf.String())
 	}
 }
+
+func TestStreamFrameSource_Lenient(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24}
+[1,"o","a"]
+[2,"x","b"]
+
+not a frame
+[3,"o","c"]
+[4,"o","d`
+
+	strict, err := player.NewStreamFrameSource(strings.NewReader(cast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	for strict.Next() {
+	}
+
+	if strict.Err() == nil {
+		t.Fatalf("Strict source must fail")
+	}
+
+	source, err := player.NewStreamFrameSource(strings.NewReader(cast), player.WithLenientParsing())
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	expected := []player.Frame{
+		{Time: 1, Type: player.OutputFrame, Data: []byte("a")},
+		{Time: 3, Type: player.OutputFrame, Data: []byte("c")},
+	}
+
+	if actual := readFrames(t, source); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+
+	var lines []int
+	for _, skipped := range source.Skipped() {
+		lines = append(lines, skipped.Line)
+	}
+
+	if !reflect.DeepEqual([]int{3, 5, 7}, lines) {
+		t.Fatalf("Unexpected skipped lines: %v", source.Skipped())
+	}
+
+	var frameErr *player.FrameUnmarshalError
+	if !errors.As(source.Skipped()[0], &frameErr) || frameErr.Index != 1 {
+		t.Fatalf("Unexpected error: %s", source.Skipped()[0])
+	}
+}
+
+func TestStreamFrameSource_Rewind(t *testing.T) {
+	cast := `{"version":2,"width":80,"height":24}
+[1,"o","a"]
+[2,"o","b"]
+`
+
+	for _, lenient := range []bool{false, true} {
+		var opts []player.StreamOption
+		if lenient {
+			opts = append(opts, player.WithLenientParsing())
+		}
+
+		source, err := player.NewStreamFrameSource(strings.NewReader(cast), opts...)
+		if err != nil {
+			t.Fatalf("Source create failed: %s", err)
+		}
+
+		if !source.CanRewind() || !source.Next() {
+			t.Fatalf("Unexpected source state")
+		}
+
+		if err = source.Rewind(); err != nil {
+			t.Fatalf("Rewind failed: %s", err)
+		}
+
+		var data string
+		for source.Next() {
+			data += string(source.Frame().Data)
+		}
+
+		if data != "ab" || source.Header().Width != 80 {
+			t.Fatalf("Unexpected frames after rewind: %q", data)
+		}
+	}
+
+	// stream without seeking
+	source, err := player.NewStreamFrameSource(struct{ io.Reader }{strings.NewReader(cast)})
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if source.CanRewind() || !errors.Is(source.Rewind(), player.ErrNotRewindable) {
+		t.Fatalf("Stream must not be rewindable")
+	}
+}
@@ -0,0 +1,137 @@
+package player
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxTTYRecRecordSize limits size of one ttyrec record to detect garbage input.
+const maxTTYRecRecordSize = 64 << 20
+
+// TTYRecFrameSource reads frames from ttyrec stream. Every record becomes an OutputFrame.
+// Record contains header of 3 little-endian uint32 values (seconds, microseconds and data length) followed by data.
+type TTYRecFrameSource struct {
+	reader io.Reader
+
+	hdr       Header
+	startTime float64
+
+	first   *ttyrecRecord
+	pending []byte // incomplete UTF-8 character from previous record
+	frame   Frame
+	err     error
+}
+
+type ttyrecRecord struct {
+	time float64
+	data []byte
+}
+
+// NewTTYRecFrameSource constructs TTYRecFrameSource. It reads first record to get recording start time.
+// Terminal size is not stored in ttyrec so it must be provided, see InferDimensions.
+func NewTTYRecFrameSource(reader io.Reader, width, height int) (*TTYRecFrameSource, error) {
+	s := &TTYRecFrameSource{
+		reader: reader,
+		hdr: Header{
+			Version: FormatVersion,
+			Width:   width,
+			Height:  height,
+		},
+	}
+
+	first, err := s.readRecord()
+	if err == nil {
+		s.first = first
+		s.startTime = first.time
+		s.hdr.Timestamp = int64(first.time)
+	} else if !errors.Is(err, io.EOF) { // EOF means empty recording
+		return nil, fmt.Errorf("read first record failed: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *TTYRecFrameSource) readRecord() (*ttyrecRecord, error) {
+	var hdr [12]byte
+	if _, err := io.ReadFull(s.reader, hdr[:]); err != nil {
+		return nil, err // io.EOF only if no bytes were read
+	}
+
+	sec := binary.LittleEndian.Uint32(hdr[0:4])
+	usec := binary.LittleEndian.Uint32(hdr[4:8])
+	length := binary.LittleEndian.Uint32(hdr[8:12])
+
+	if length > maxTTYRecRecordSize {
+		return nil, fmt.Errorf("record too large: %d bytes", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(s.reader, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return &ttyrecRecord{
+		time: float64(sec) + float64(usec)/1e6,
+		data: data,
+	}, nil
+}
+
+// Header returns asciinema-v2 header.
+func (s *TTYRecFrameSource) Header() Header { return s.hdr }
+
+// Next advances to next available frame. It must return false if error occurs or there is no more frames.
+func (s *TTYRecFrameSource) Next() bool {
+	for {
+		record := s.first
+		s.first = nil
+
+		if record == nil {
+			var err error
+
+			record, err = s.readRecord()
+			if errors.Is(err, io.EOF) {
+				if len(s.pending) == 0 {
+					return false
+				}
+
+				// flush broken character as is
+				s.frame = Frame{Time: s.frame.Time, Type: OutputFrame, Data: s.pending}
+				s.pending = nil
+
+				return true
+			}
+
+			if err != nil {
+				s.err = fmt.Errorf("read record failed: %w", err)
+				return false
+			}
+		}
+
+		data, pending := splitIncompleteUTF8(append(s.pending, record.data...))
+		s.pending = append([]byte(nil), pending...)
+
+		if len(data) == 0 {
+			continue
+		}
+
+		s.frame = Frame{
+			Time: record.time - s.startTime,
+			Type: OutputFrame,
+			Data: data,
+		}
+
+		return true
+	}
+}
+
+// Frame returns current frame. It becomes unusable after Next call.
+func (s *TTYRecFrameSource) Frame() Frame { return s.frame }
+
+// Err returns error if it happens during iteration.
+func (s *TTYRecFrameSource) Err() error { return s.err }
@@ -0,0 +1,190 @@
+package player_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func readFrames(t *testing.T, src player.FrameSource) []player.Frame {
+	t.Helper()
+
+	var frames []player.Frame
+	for src.Next() {
+		frame := src.Frame()
+		frame.Data = append([]byte(nil), frame.Data...)
+		frames = append(frames, frame)
+	}
+
+	if err := src.Err(); err != nil {
+		t.Fatalf("Source error: %s", err)
+	}
+
+	return frames
+}
+
+func TestTTYRec_RoundTrip(t *testing.T) {
+	cast, err := os.ReadFile(filepath.Join("testdata", "test.cast"))
+	if err != nil {
+		t.Fatalf("Cast read failed: %s", err)
+	}
+
+	source, err := player.NewStreamFrameSource(bytes.NewReader(cast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	hdr := source.Header()
+	hdr.Timestamp = 1530264003
+
+	var ttyrec bytes.Buffer
+	if err = player.CopyFrames(player.NewTTYRecWriter(&ttyrec, hdr), source); err != nil {
+		t.Fatalf("Write ttyrec failed: %s", err)
+	}
+
+	ttyrecSource, err := player.NewTTYRecFrameSource(bytes.NewReader(ttyrec.Bytes()), hdr.Width, hdr.Height)
+	if err != nil {
+		t.Fatalf("Ttyrec source create failed: %s", err)
+	}
+
+	if ttyrecSource.Header().Timestamp != hdr.Timestamp {
+		t.Fatalf("Unexpected timestamp %d", ttyrecSource.Header().Timestamp)
+	}
+
+	var converted bytes.Buffer
+
+	writer, err := player.NewStreamFrameWriter(&converted, ttyrecSource.Header())
+	if err != nil {
+		t.Fatalf("Writer create failed: %s", err)
+	}
+
+	if err = player.CopyFrames(writer, ttyrecSource); err != nil {
+		t.Fatalf("Write cast failed: %s", err)
+	}
+
+	source, err = player.NewStreamFrameSource(bytes.NewReader(cast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	convertedSource, err := player.NewStreamFrameSource(&converted)
+	if err != nil {
+		t.Fatalf("Converted source create failed: %s", err)
+	}
+
+	var expected []player.Frame
+	for _, frame := range readFrames(t, source) {
+		if frame.Type == player.OutputFrame {
+			expected = append(expected, frame)
+		}
+	}
+
+	actual := readFrames(t, convertedSource)
+	if len(expected) != len(actual) {
+		t.Fatalf("Frame count mismatch: expected %d, got %d", len(expected), len(actual))
+	}
+
+	// first frame time becomes zero point
+	offset := expected[0].Time
+	for i := range expected {
+		if math.Abs(expected[i].Time-offset-actual[i].Time) > 1e-6 || !bytes.Equal(expected[i].Data, actual[i].Data) {
+			t.Fatalf("Frame %d mismatch: expected %+v, got %+v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestTTYRecFrameSource_SplitCharacters(t *testing.T) {
+	var ttyrec bytes.Buffer
+
+	w := player.NewTTYRecWriter(&ttyrec, player.Header{})
+	for i, data := range []string{"a\xe2\x8f", "\x8eb\xe2", "\x8f", "\x8e"} {
+		if err := w.WriteFrame(player.Frame{Time: float64(i), Type: player.OutputFrame, Data: []byte(data)}); err != nil {
+			t.Fatalf("Write failed: %s", err)
+		}
+	}
+
+	src, err := player.NewTTYRecFrameSource(&ttyrec, 80, 24)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	expected := []player.Frame{
+		{Time: 0, Type: player.OutputFrame, Data: []byte("a")},
+		{Time: 1, Type: player.OutputFrame, Data: []byte("⏎b")},
+		{Time: 3, Type: player.OutputFrame, Data: []byte("⏎")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestTTYRecFrameSource_Truncated(t *testing.T) {
+	var ttyrec bytes.Buffer
+
+	w := player.NewTTYRecWriter(&ttyrec, player.Header{})
+	if err := w.WriteFrame(player.Frame{Type: player.OutputFrame, Data: []byte("abc")}); err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+
+	if err := w.WriteFrame(player.Frame{Type: player.OutputFrame, Data: []byte("def")}); err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+
+	src, err := player.NewTTYRecFrameSource(bytes.NewReader(ttyrec.Bytes()[:ttyrec.Len()-1]), 80, 24)
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if !src.Next() {
+		t.Fatalf("First frame expected")
+	}
+
+	if src.Next() || src.Err() == nil {
+		t.Fatalf("Error expected for truncated record")
+	}
+}
+
+func TestInferDimensions(t *testing.T) {
+	cast, err := os.ReadFile("app-demo.cast")
+	if err != nil {
+		t.Fatalf("Cast read failed: %s", err)
+	}
+
+	source, err := player.NewStreamFrameSource(bytes.NewReader(cast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	width, height, err := player.InferDimensions(source)
+	if err != nil {
+		t.Fatalf("Infer failed: %s", err)
+	}
+
+	// recorded on 100x25 terminal, but screen was not fully used
+	if width < 99 || width > 100 || height != player.DefaultHeight {
+		t.Fatalf("Unexpected dimensions %dx%d", width, height)
+	}
+
+	source = mustSourceFromString(t, `{"version":2,"width":0,"height":0}`+"\n"+`[0.1,"o","\u001b[8;40;132t\u001b[1;38r"]`)
+
+	if width, height, err = player.InferDimensions(source); err != nil || width != 132 || height != 40 {
+		t.Fatalf("Unexpected dimensions %dx%d, error %v", width, height, err)
+	}
+}
+
+func mustSourceFromString(t *testing.T, cast string) *player.StreamFrameSource {
+	t.Helper()
+
+	source, err := player.NewStreamFrameSource(bytes.NewBufferString(cast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	return source
+}
@@ -0,0 +1,49 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// FrameWriter describes frames destination.
+type FrameWriter interface {
+	// WriteFrame writes frame to destination.
+	WriteFrame(Frame) error
+}
+
+// CopyFrames writes all frames from FrameSource to FrameWriter.
+func CopyFrames(dst FrameWriter, src FrameSource) error {
+	for src.Next() {
+		if err := dst.WriteFrame(src.Frame()); err != nil {
+			return fmt.Errorf("write frame failed: %w", err)
+		}
+	}
+
+	return src.Err()
+}
+
+// StreamFrameWriter writes frames in asciinema-v2 format to io.Writer.
+type StreamFrameWriter struct {
+	enc *json.Encoder
+}
+
+// NewStreamFrameWriter constructs StreamFrameWriter. It writes Header to output stream.
+// Header version is always set to FormatVersion.
+func NewStreamFrameWriter(writer io.Writer, hdr Header) (*StreamFrameWriter, error) {
+	enc := json.NewEncoder(writer)
+
+	hdr.Version = FormatVersion
+	if err := enc.Encode(hdr); err != nil {
+		return nil, fmt.Errorf("write header failed: %w", err)
+	}
+
+	return &StreamFrameWriter{
+		enc: enc,
+	}, nil
+}
+
+// WriteFrame writes frame as JSON-array on separate line.
+func (w *StreamFrameWriter) WriteFrame(frame Frame) error {
+	return w.enc.Encode(frame)
+}
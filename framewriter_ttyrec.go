@@ -0,0 +1,53 @@
+package player
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// TTYRecWriter writes frames in ttyrec format to io.Writer.
+// Only OutputFrame is written because ttyrec doesn't support other frame types.
+type TTYRecWriter struct {
+	writer    io.Writer
+	startTime float64
+}
+
+// NewTTYRecWriter constructs TTYRecWriter. Record times are counted from Header.Timestamp.
+func NewTTYRecWriter(writer io.Writer, hdr Header) *TTYRecWriter {
+	return &TTYRecWriter{
+		writer:    writer,
+		startTime: float64(hdr.Timestamp),
+	}
+}
+
+// WriteFrame writes frame as ttyrec record.
+func (w *TTYRecWriter) WriteFrame(frame Frame) error {
+	if frame.Type != OutputFrame {
+		return nil
+	}
+
+	if uint64(len(frame.Data)) > math.MaxUint32 {
+		return fmt.Errorf("frame too large: %d bytes", len(frame.Data))
+	}
+
+	sec, frac := math.Modf(w.startTime + frame.Time)
+	usec := math.Round(frac * 1e6)
+	if usec >= 1e6 {
+		sec, usec = sec+1, 0
+	}
+
+	var hdr [12]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(sec))
+	binary.LittleEndian.PutUint32(hdr[4:8], uint32(usec))
+	binary.LittleEndian.PutUint32(hdr[8:12], uint32(len(frame.Data)))
+
+	if _, err := w.writer.Write(hdr[:]); err != nil {
+		return err
+	}
+
+	_, err := w.writer.Write(frame.Data)
+
+	return err
+}
@@ -3,6 +3,11 @@ module github.com/xakep666/asciinema-player/v3
 go 1.17
 
 require (
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
+	golang.org/x/image v0.5.0
 	golang.org/x/sys v0.1.0
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
+	nhooyr.io/websocket v1.8.7
 )
+
+require github.com/klauspost/compress v1.10.3 // indirect
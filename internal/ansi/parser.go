@@ -0,0 +1,445 @@
+// Package ansi implements incremental parser of terminal output (text, control characters and escape sequences).
+// State machine is based on DEC ANSI parser described at https://vt100.net/emu/dec_ansi_parser.
+package ansi
+
+import (
+	"unicode/utf8"
+)
+
+// Kind is a kind of parsed Sequence.
+type Kind int
+
+const (
+	// Print is a printable character.
+	Print Kind = iota
+
+	// Control is a C0 control character (i.e. "\r", "\n", "\b").
+	Control
+
+	// ESC is an escape sequence without CSI, OSC or DCS introducer (i.e. "ESC c", "ESC 7", "ESC ( B").
+	ESC
+
+	// CSI is a control sequence (i.e. "ESC [ 1 ; 2 H").
+	CSI
+
+	// OSC is an operating system command (i.e. "ESC ] 0 ; title BEL").
+	OSC
+
+	// DCS is a device control string.
+	DCS
+
+	// String is a SOS, PM or APC string. Their contents are ignored by terminals.
+	String
+)
+
+const (
+	maxParams     = 32
+	maxStringData = 64 << 10
+)
+
+const (
+	bel = 0x07
+	can = 0x18
+	sub = 0x1a
+	esc = 0x1b
+	del = 0x7f
+)
+
+// Sequence is a parsed unit of terminal output.
+type Sequence struct {
+	Kind Kind
+
+	// Rune is a printed character for Print.
+	Rune rune
+
+	// Byte is a control character for Control.
+	Byte byte
+
+	// Prefix is a private marker ('<', '=', '>' or '?') of CSI or DCS. Zero if missing.
+	Prefix byte
+
+	// Params of CSI or DCS. Missing params are zero.
+	Params []int
+
+	// Intermediates of ESC, CSI or DCS.
+	Intermediates []byte
+
+	// Final byte of ESC, CSI or DCS.
+	Final byte
+
+	// Data of OSC, DCS or String. It's truncated if too long.
+	Data []byte
+
+	// Offset is a position of first byte of sequence in whole parsed stream.
+	Offset int64
+
+	// Raw contains all bytes of sequence as they were in stream.
+	Raw []byte
+}
+
+// Param returns param with given index or default value if it's missing or zero.
+func (s *Sequence) Param(i, def int) int {
+	if i >= len(s.Params) || s.Params[i] == 0 {
+		return def
+	}
+
+	return s.Params[i]
+}
+
+type state int
+
+const (
+	stateGround state = iota
+	stateEscape
+	stateEscapeIntermediate
+	stateCSIEntry
+	stateCSIParam
+	stateCSIIntermediate
+	stateCSIIgnore
+	stateOSCString
+	stateDCSEntry
+	stateDCSParam
+	stateDCSIntermediate
+	stateDCSPassthrough
+	stateDCSIgnore
+	stateString
+)
+
+// Parser is an incremental terminal output parser. Input may be split at any position.
+// Zero value is ready to use.
+type Parser struct {
+	state state
+	seq   Sequence
+
+	param    int
+	hasParam bool
+
+	// stringEsc is true if ESC was met inside string, it may be a first byte of ST ("ESC \").
+	stringEsc bool
+
+	utf8Buf [utf8.UTFMax]byte
+	utf8Len int
+
+	offset int64
+}
+
+// Feed parses data and calls fn for every complete sequence. Sequence passed to fn must not be retained.
+func (p *Parser) Feed(data []byte, fn func(*Sequence)) {
+	for _, b := range data {
+		p.feedByte(b, fn)
+		p.offset++
+	}
+}
+
+// Pending returns true if parser is in the middle of sequence.
+func (p *Parser) Pending() bool {
+	return p.state != stateGround || p.utf8Len > 0
+}
+
+func (p *Parser) begin(kind Kind) {
+	p.seq.Kind = kind
+	p.seq.Rune = 0
+	p.seq.Byte = 0
+	p.seq.Prefix = 0
+	p.seq.Params = p.seq.Params[:0]
+	p.seq.Intermediates = p.seq.Intermediates[:0]
+	p.seq.Final = 0
+	p.seq.Data = p.seq.Data[:0]
+	p.param = 0
+	p.hasParam = false
+	p.stringEsc = false
+}
+
+func (p *Parser) dispatch(fn func(*Sequence)) {
+	fn(&p.seq)
+	p.state = stateGround
+	p.seq.Raw = p.seq.Raw[:0]
+}
+
+func (p *Parser) feedByte(b byte, fn func(*Sequence)) {
+	if p.utf8Len > 0 {
+		if b&0xc0 == 0x80 {
+			p.utf8Buf[p.utf8Len] = b
+			p.utf8Len++
+
+			if utf8.FullRune(p.utf8Buf[:p.utf8Len]) {
+				r, _ := utf8.DecodeRune(p.utf8Buf[:p.utf8Len])
+				p.printRune(r, p.utf8Buf[:p.utf8Len], p.offset+1-int64(p.utf8Len), fn)
+			}
+
+			return
+		}
+
+		// broken sequence, replace it with RuneError
+		p.printRune(utf8.RuneError, p.utf8Buf[:p.utf8Len], p.offset-int64(p.utf8Len), fn)
+	}
+
+	if p.stringEsc {
+		p.stringEsc = false
+
+		if b == '\\' { // ST
+			p.seq.Raw = append(p.seq.Raw, b)
+			p.finishString(fn)
+
+			return
+		}
+
+		// ESC inside string followed by something other is a new escape sequence, string is aborted
+		p.seq.Raw = append(p.seq.Raw[:0], esc)
+		p.seq.Offset = p.offset - 1
+		p.begin(ESC)
+		p.state = stateEscape
+	}
+
+	// these bytes interrupt any sequence
+	switch b {
+	case can, sub:
+		if p.state != stateGround {
+			p.seq.Raw = p.seq.Raw[:0]
+			p.state = stateGround
+		}
+
+		p.control(b, fn)
+
+		return
+	case esc:
+		switch p.state {
+		case stateOSCString, stateDCSPassthrough, stateDCSIgnore, stateString:
+			p.stringEsc = true
+			p.seq.Raw = append(p.seq.Raw, b)
+
+			return
+		}
+
+		p.seq.Raw = append(p.seq.Raw[:0], b)
+		p.seq.Offset = p.offset
+		p.begin(ESC)
+		p.state = stateEscape
+
+		return
+	}
+
+	if p.state != stateGround {
+		p.seq.Raw = append(p.seq.Raw, b)
+	}
+
+	switch p.state {
+	case stateGround:
+		p.ground(b, fn)
+	case stateEscape:
+		p.escape(b, fn)
+	case stateEscapeIntermediate:
+		p.escapeIntermediate(b, fn)
+	case stateCSIEntry, stateCSIParam, stateCSIIntermediate, stateCSIIgnore:
+		p.csi(b, fn)
+	case stateOSCString:
+		p.oscString(b, fn)
+	case stateDCSEntry, stateDCSParam, stateDCSIntermediate:
+		p.dcs(b)
+	case stateDCSPassthrough, stateDCSIgnore, stateString:
+		p.passthrough(b)
+	}
+}
+
+func (p *Parser) ground(b byte, fn func(*Sequence)) {
+	switch {
+	case b < 0x20:
+		p.control(b, fn)
+	case b == del:
+		// ignored
+	case b < utf8.RuneSelf:
+		p.utf8Buf[0] = b
+		p.printRune(rune(b), p.utf8Buf[:1], p.offset, fn)
+	default:
+		p.utf8Buf[0] = b
+		p.utf8Len = 1
+
+		if b&0xc0 == 0x80 || b >= 0xf8 { // unexpected continuation or invalid byte
+			p.printRune(utf8.RuneError, p.utf8Buf[:1], p.offset, fn)
+		}
+	}
+}
+
+func (p *Parser) printRune(r rune, raw []byte, offset int64, fn func(*Sequence)) {
+	p.utf8Len = 0
+	p.begin(Print)
+	p.seq.Rune = r
+	p.seq.Offset = offset
+	p.seq.Raw = append(p.seq.Raw[:0], raw...)
+	fn(&p.seq)
+	p.seq.Raw = p.seq.Raw[:0]
+}
+
+// control reports control character. It may be called in the middle of other sequence so it doesn't touch it.
+func (p *Parser) control(b byte, fn func(*Sequence)) {
+	fn(&Sequence{Kind: Control, Byte: b, Offset: p.offset, Raw: []byte{b}})
+}
+
+func (p *Parser) escape(b byte, fn func(*Sequence)) {
+	switch {
+	case b < 0x20:
+		p.executeInside(b, fn)
+	case b == '[':
+		p.seq.Kind = CSI
+		p.state = stateCSIEntry
+	case b == ']':
+		p.seq.Kind = OSC
+		p.state = stateOSCString
+	case b == 'P':
+		p.seq.Kind = DCS
+		p.state = stateDCSEntry
+	case b == 'X' || b == '^' || b == '_':
+		p.seq.Kind = String
+		p.seq.Final = b
+		p.state = stateString
+	case b < 0x30:
+		p.seq.Intermediates = append(p.seq.Intermediates, b)
+		p.state = stateEscapeIntermediate
+	case b < del:
+		p.seq.Final = b
+		p.dispatch(fn)
+	}
+}
+
+func (p *Parser) escapeIntermediate(b byte, fn func(*Sequence)) {
+	switch {
+	case b < 0x20:
+		p.executeInside(b, fn)
+	case b < 0x30:
+		p.seq.Intermediates = append(p.seq.Intermediates, b)
+	case b < del:
+		p.seq.Final = b
+		p.dispatch(fn)
+	}
+}
+
+// executeInside executes control character met inside sequence. It doesn't become a part of sequence.
+func (p *Parser) executeInside(b byte, fn func(*Sequence)) {
+	p.seq.Raw = p.seq.Raw[:len(p.seq.Raw)-1]
+	p.control(b, fn)
+}
+
+func (p *Parser) collectParam(b byte) {
+	switch {
+	case b >= '0' && b <= '9':
+		if p.param < 1<<20 {
+			p.param = p.param*10 + int(b-'0')
+		}
+
+		p.hasParam = true
+	case b == ';' || b == ':':
+		p.pushParam()
+		p.hasParam = true
+	}
+}
+
+func (p *Parser) pushParam() {
+	if len(p.seq.Params) < maxParams {
+		p.seq.Params = append(p.seq.Params, p.param)
+	}
+
+	p.param = 0
+}
+
+func (p *Parser) finishParams() {
+	if p.hasParam {
+		p.pushParam()
+	}
+}
+
+func (p *Parser) csi(b byte, fn func(*Sequence)) {
+	switch {
+	case b < 0x20:
+		p.executeInside(b, fn)
+	case b < 0x30: // intermediate
+		if p.state == stateCSIIgnore {
+			return
+		}
+
+		p.seq.Intermediates = append(p.seq.Intermediates, b)
+		p.state = stateCSIIntermediate
+	case b < 0x40: // param or prefix
+		switch {
+		case p.state == stateCSIIgnore:
+		case b >= '<' && p.state == stateCSIEntry:
+			p.seq.Prefix = b
+			p.state = stateCSIParam
+		case b >= '<' || p.state == stateCSIIntermediate:
+			p.state = stateCSIIgnore
+		default:
+			p.collectParam(b)
+			p.state = stateCSIParam
+		}
+	case b < del:
+		if p.state == stateCSIIgnore {
+			p.seq.Raw = p.seq.Raw[:0]
+			p.state = stateGround
+
+			return
+		}
+
+		p.finishParams()
+		p.seq.Final = b
+		p.dispatch(fn)
+	}
+}
+
+func (p *Parser) appendData(b byte) {
+	if len(p.seq.Data) < maxStringData {
+		p.seq.Data = append(p.seq.Data, b)
+	}
+}
+
+// finishString dispatches OSC, DCS or String after terminator.
+func (p *Parser) finishString(fn func(*Sequence)) {
+	if p.state == stateDCSIgnore {
+		p.seq.Raw = p.seq.Raw[:0]
+		p.state = stateGround
+
+		return
+	}
+
+	p.dispatch(fn)
+}
+
+func (p *Parser) oscString(b byte, fn func(*Sequence)) {
+	switch {
+	case b == bel:
+		p.dispatch(fn)
+	case b < 0x20:
+		// ignored
+	default:
+		p.appendData(b)
+	}
+}
+
+func (p *Parser) dcs(b byte) {
+	switch {
+	case b < 0x20:
+		// ignored
+	case b < 0x30:
+		p.seq.Intermediates = append(p.seq.Intermediates, b)
+		p.state = stateDCSIntermediate
+	case b < 0x40:
+		switch {
+		case b >= '<' && p.state == stateDCSEntry:
+			p.seq.Prefix = b
+			p.state = stateDCSParam
+		case b >= '<' || p.state == stateDCSIntermediate:
+			p.state = stateDCSIgnore
+		default:
+			p.collectParam(b)
+			p.state = stateDCSParam
+		}
+	case b < del:
+		p.finishParams()
+		p.seq.Final = b
+		p.state = stateDCSPassthrough
+	}
+}
+
+func (p *Parser) passthrough(b byte) {
+	if p.state != stateDCSIgnore && b != del {
+		p.appendData(b)
+	}
+}
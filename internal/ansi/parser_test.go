@@ -0,0 +1,89 @@
+package ansi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func describe(seq *Sequence) string {
+	switch seq.Kind {
+	case Print:
+		return fmt.Sprintf("print %q@%d", seq.Rune, seq.Offset)
+	case Control:
+		return fmt.Sprintf("control %#x@%d", seq.Byte, seq.Offset)
+	case ESC:
+		return fmt.Sprintf("esc %q %c@%d", seq.Intermediates, seq.Final, seq.Offset)
+	case CSI:
+		return fmt.Sprintf("csi %q %v %q %c@%d raw %q", seq.Prefix, seq.Params, seq.Intermediates, seq.Final, seq.Offset, seq.Raw)
+	case OSC:
+		return fmt.Sprintf("osc %q@%d raw %q", seq.Data, seq.Offset, seq.Raw)
+	case DCS:
+		return fmt.Sprintf("dcs %v %c %q@%d", seq.Params, seq.Final, seq.Data, seq.Offset)
+	default:
+		return fmt.Sprintf("string %c %q@%d", seq.Final, seq.Data, seq.Offset)
+	}
+}
+
+func TestParser(t *testing.T) {
+	input := "aé\r\n\x1b[?1049h\x1b[1;31mb\x1b]0;title\x07\x1b(B\x1b]2;t2\x1b\\\x1bP1$qm\x1b\\\x1b[3\nA"
+
+	expected := []string{
+		`print 'a'@0`,
+		`print 'é'@1`,
+		`control 0xd@3`,
+		`control 0xa@4`,
+		`csi '?' [1049] "" h@5 raw "\x1b[?1049h"`,
+		`csi '\x00' [1 31] "" m@13 raw "\x1b[1;31m"`,
+		`print 'b'@20`,
+		`osc "0;title"@21 raw "\x1b]0;title\a"`,
+		`esc "(" B@31`,
+		`osc "2;t2"@34 raw "\x1b]2;t2\x1b\\"`,
+		`dcs [1] q "m"@42`,
+		`control 0xa@53`,
+		`csi '\x00' [3] "" A@50 raw "\x1b[3A"`,
+	}
+
+	// feed input split at every possible position
+	for split := 0; split <= len(input); split++ {
+		var (
+			p      Parser
+			actual []string
+		)
+
+		fn := func(seq *Sequence) { actual = append(actual, describe(seq)) }
+
+		p.Feed([]byte(input[:split]), fn)
+		p.Feed([]byte(input[split:]), fn)
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Split at %d, unexpected sequences:\n%s", split, strings.Join(actual, "\n"))
+		}
+
+		if p.Pending() {
+			t.Fatalf("Parser must not be pending")
+		}
+	}
+}
+
+func TestParser_Aborted(t *testing.T) {
+	var (
+		p      Parser
+		actual []string
+	)
+
+	p.Feed([]byte("\x1b]0;abc\x1bc\x1b[1\x18x\xffy"), func(seq *Sequence) { actual = append(actual, describe(seq)) })
+
+	expected := []string{
+		`esc "" c@7`,
+		`control 0x18@12`,
+		`print 'x'@13`,
+		`print '�'@14`,
+		`print 'y'@15`,
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected sequences:\n%s", strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,90 @@
+// Package remote implements recording menu and playback keys for terminals of remote clients (SSH, telnet).
+package remote
+
+import (
+	"io"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const (
+	space = 0x20
+	ctrlC = 0x03
+	ctrlD = 0x04
+)
+
+// Console is a remote client terminal.
+type Console struct {
+	// Output of client terminal.
+	Output io.Writer
+
+	// Input contains keystrokes of client, it's closed when client closes input.
+	Input <-chan byte
+
+	// Closed is closed when console is closed.
+	Closed <-chan struct{}
+}
+
+// Control maps keystrokes to playback control until done is closed. Playback is stopped if client closes input.
+func (c *Console) Control(control player.PlaybackControl, done <-chan struct{}) {
+	keys := player.NewKeyControl(control)
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		select {
+		case <-done:
+			return
+		case b, ok := <-c.Input:
+			if !ok {
+				control.Stop()
+				return
+			}
+
+			keys.Key(b)
+		}
+	}
+}
+
+// ReadKey returns next keystroke, false is returned if client closed input.
+func (c *Console) ReadKey() (byte, bool) {
+	select {
+	case b, ok := <-c.Input:
+		return b, ok
+	case <-c.Closed:
+		return 0, false
+	}
+}
+
+// ReadLine reads line echoing it to client. Backspace is supported, false is returned if client closed input
+// or pressed "Ctrl+C" or "Ctrl+D".
+func (c *Console) ReadLine() (string, bool) {
+	var line []byte
+
+	for {
+		b, ok := c.ReadKey()
+		if !ok {
+			return "", false
+		}
+
+		switch {
+		case b == '\r' || b == '\n':
+			c.Output.Write([]byte("\r\n"))
+			return string(line), true
+		case b == ctrlC || b == ctrlD:
+			return "", false
+		case b == 0x7f || b == '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				c.Output.Write([]byte("\b \b"))
+			}
+		case b >= space && b < 0x7f:
+			line = append(line, b)
+			c.Output.Write([]byte{b})
+		}
+	}
+}
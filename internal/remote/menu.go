@@ -0,0 +1,148 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// Menu lets client choose recording and plays it, menu is shown again after playback.
+type Menu struct {
+	// FS contains recordings, all "*.cast" files are listed in menu.
+	FS fs.FS
+
+	// Options for Player.
+	Options []player.Option
+}
+
+// Run shows menu until client quits. Terminal is used for playback, its Control is replaced by console keys.
+func (m *Menu) Run(term player.Terminal, console *Console) error {
+	var notice string
+
+	for {
+		files, err := listCasts(m.FS)
+		if err != nil {
+			return fmt.Errorf("list recordings failed: %w", err)
+		}
+
+		var b strings.Builder
+
+		b.WriteString("\033cRecordings:\r\n")
+
+		for i, file := range files {
+			fmt.Fprintf(&b, "%4d) %s\r\n", i+1, file)
+		}
+
+		if notice != "" {
+			fmt.Fprintf(&b, "\r\n%s\r\n", notice)
+		}
+
+		b.WriteString("\r\nKeys: Space - pause, q - stop, Left/Right - seek.\r\nChoose recording (q to quit): ")
+
+		if _, err = console.Output.Write([]byte(b.String())); err != nil {
+			return err
+		}
+
+		line, ok := console.ReadLine()
+		if !ok || line == "q" {
+			return nil
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil || n < 1 || n > len(files) {
+			notice = fmt.Sprintf("Unknown recording %q", line)
+			continue
+		}
+
+		notice = ""
+
+		if err = m.Play(term, console, files[n-1]); err != nil {
+			notice = fmt.Sprintf("Playback of %s failed: %s", files[n-1], err)
+			continue
+		}
+
+		if _, err = console.Output.Write([]byte("\033[0m\r\nPlayback finished, press any key to return to menu.")); err != nil {
+			return err
+		}
+
+		if _, ok = console.ReadKey(); !ok {
+			return nil
+		}
+	}
+}
+
+// Play plays recording from FS. Control of terminal is replaced by console keys.
+func (m *Menu) Play(term player.Terminal, console *Console, name string) error {
+	file, err := m.FS.Open(name)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	src, err := player.NewStreamFrameSource(file)
+	if err != nil {
+		return err
+	}
+
+	pb := &playback{Terminal: term, console: console, done: make(chan struct{}), exited: make(chan struct{})}
+
+	p, err := player.NewPlayer(src, pb, m.Options...)
+	if errors.Is(err, player.ErrSmallTerminal) {
+		hdr := src.Header()
+		return fmt.Errorf("%w, %dx%d required", err, hdr.Width, hdr.Height)
+	} else if err != nil {
+		return err
+	}
+
+	defer pb.Close()
+
+	return p.Start()
+}
+
+// playback is a terminal for single playback: Close stops control loop, so next keystrokes go to menu.
+type playback struct {
+	player.Terminal
+
+	console *Console
+	done    chan struct{}
+	exited  chan struct{}
+}
+
+func (p *playback) Control(control player.PlaybackControl) {
+	defer close(p.exited)
+	p.console.Control(control, p.done)
+}
+
+func (p *playback) Close() error {
+	close(p.done)
+	<-p.exited
+
+	return nil
+}
+
+func listCasts(fsys fs.FS) ([]string, error) {
+	var files []string
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path.Ext(name) == ".cast" && d.Type().IsRegular() {
+			files = append(files, name)
+		}
+
+		return nil
+	})
+
+	sort.Strings(files)
+
+	return files, err
+}
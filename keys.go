@@ -0,0 +1,53 @@
+package player
+
+const (
+	space  = 0x20
+	ctrlC  = 0x03
+	escape = 0x1b
+
+	// seekStep is a seek distance of arrow keys in seconds.
+	seekStep = 5
+)
+
+// KeyControl maps keystrokes of terminal to playback control so all terminals behave the same:
+// space pauses, "q" and "Ctrl+C" stop, right and left arrows seek 5 seconds forward and backward.
+type KeyControl struct {
+	control PlaybackControl
+	state   int // position in "ESC [ letter" sequence of arrow key
+}
+
+// NewKeyControl constructs KeyControl calling methods of given PlaybackControl.
+func NewKeyControl(control PlaybackControl) *KeyControl {
+	return &KeyControl{control: control}
+}
+
+// Key handles next keystroke. Escape sequences may be split between calls.
+func (k *KeyControl) Key(b byte) {
+	switch {
+	case k.state == 1 && b == '[':
+		k.state = 2
+		return
+	case k.state == 2:
+		k.state = 0
+
+		switch b {
+		case 'C':
+			k.control.Seek(k.control.Position() + seekStep)
+		case 'D':
+			k.control.Seek(k.control.Position() - seekStep)
+		}
+
+		return
+	}
+
+	k.state = 0
+
+	switch b {
+	case space:
+		k.control.Pause()
+	case 'q', ctrlC:
+		k.control.Stop()
+	case escape:
+		k.state = 1
+	}
+}
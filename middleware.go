@@ -0,0 +1,256 @@
+package player
+
+import (
+	"math"
+)
+
+// Filter returns FrameSource containing only frames for which keep returns true.
+func Filter(src FrameSource, keep func(Frame) bool) FrameSource {
+	return &filterFrameSource{FrameSource: src, keep: keep}
+}
+
+type filterFrameSource struct {
+	FrameSource
+	keep func(Frame) bool
+}
+
+func (s *filterFrameSource) Next() bool {
+	for s.FrameSource.Next() {
+		if s.keep(s.FrameSource.Frame()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Map returns FrameSource with every frame replaced by result of fn. Frame data passed to fn becomes unusable after next frame read.
+// Times returned by fn must not decrease.
+func Map(src FrameSource, fn func(Frame) Frame) FrameSource {
+	return &mapFrameSource{FrameSource: src, fn: fn}
+}
+
+type mapFrameSource struct {
+	FrameSource
+	fn    func(Frame) Frame
+	frame Frame
+}
+
+func (s *mapFrameSource) Next() bool {
+	if !s.FrameSource.Next() {
+		return false
+	}
+
+	s.frame = s.fn(s.FrameSource.Frame())
+
+	return true
+}
+
+func (s *mapFrameSource) Frame() Frame { return s.frame }
+
+// TimeShift returns FrameSource with frames moved by offset seconds. Frames moved before zero are played at zero.
+// Header timestamp is adjusted so frames keep their wall clock time.
+func TimeShift(src FrameSource, offset float64) FrameSource {
+	return &headerFrameSource{
+		FrameSource: Map(src, func(frame Frame) Frame {
+			frame.Time = math.Max(frame.Time+offset, 0)
+			return frame
+		}),
+		header: func(hdr Header) Header {
+			if hdr.Timestamp != 0 {
+				hdr.Timestamp -= int64(offset)
+			}
+
+			return hdr
+		},
+	}
+}
+
+// TimeScale returns FrameSource with frame times multiplied by factor. Factor less than 1 speeds up playback.
+// Non-positive factor is ignored.
+func TimeScale(src FrameSource, factor float64) FrameSource {
+	if factor <= 0 {
+		return src
+	}
+
+	return Map(src, func(frame Frame) Frame {
+		frame.Time *= factor
+		return frame
+	})
+}
+
+// Clip returns FrameSource containing frames within [start, end) time range, timeline is shifted to begin at zero.
+// Unlike Cut preceding output is dropped. Reading stops at first frame after range. Non-positive end means end of recording.
+func Clip(src FrameSource, start, end float64) FrameSource {
+	start = math.Max(start, 0)
+	if end <= 0 {
+		end = math.Inf(1)
+	}
+
+	return TimeShift(&clipFrameSource{FrameSource: src, start: start, end: end}, -start)
+}
+
+type clipFrameSource struct {
+	FrameSource
+	start, end float64
+	done       bool
+}
+
+func (s *clipFrameSource) Next() bool {
+	for !s.done && s.FrameSource.Next() {
+		switch time := s.FrameSource.Frame().Time; {
+		case time >= s.end:
+			s.done = true
+
+			// frame after range is left for next reader of the same source, see Splice
+			if u, ok := s.FrameSource.(interface{ Unread() }); ok {
+				u.Unread()
+			}
+		case time >= s.start:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Coalesce returns FrameSource where adjacent output or input frames within window seconds from first of them are merged into one.
+// It reduces number of frames in recordings written by small chunks.
+func Coalesce(src FrameSource, window float64) FrameSource {
+	return &coalesceFrameSource{src: src, window: window}
+}
+
+type coalesceFrameSource struct {
+	src    FrameSource
+	window float64
+
+	frame   Frame
+	next    Frame
+	hasNext bool
+	done    bool
+}
+
+func (s *coalesceFrameSource) Header() Header { return s.src.Header() }
+
+func (s *coalesceFrameSource) Next() bool {
+	if !s.hasNext {
+		if s.done || !s.src.Next() {
+			return false
+		}
+
+		s.next = s.src.Frame()
+	}
+
+	s.frame = s.next
+	s.frame.Data = append([]byte(nil), s.frame.Data...)
+	s.hasNext = false
+	s.done = true
+
+	for s.src.Next() {
+		s.next = s.src.Frame()
+
+		mergeable := s.frame.Type == OutputFrame || s.frame.Type == InputFrame
+		if !mergeable || s.next.Type != s.frame.Type || s.next.Time-s.frame.Time > s.window {
+			s.hasNext, s.done = true, false
+			break
+		}
+
+		s.frame.Data = append(s.frame.Data, s.next.Data...)
+	}
+
+	return true
+}
+
+func (s *coalesceFrameSource) Frame() Frame { return s.frame }
+
+func (s *coalesceFrameSource) Err() error { return s.src.Err() }
+
+// headerFrameSource adjusts header of FrameSource.
+type headerFrameSource struct {
+	FrameSource
+	header func(Header) Header
+}
+
+func (s *headerFrameSource) Header() Header { return s.header(s.FrameSource.Header()) }
+
+// Concat returns FrameSource playing sources one after another with gap (in seconds) between them.
+// Terminal is reset before every next source, resize frame is inserted if source dimensions differ from header.
+// Header is taken from first source, dimensions are maximal of all sources.
+func Concat(gap float64, sources ...FrameSource) FrameSource {
+	return &concatFrameSource{sources: sources, gap: math.Max(gap, 0)}
+}
+
+type concatFrameSource struct {
+	sources []FrameSource
+	gap     float64
+
+	index    int
+	offset   float64
+	lastTime float64
+	queue    []Frame
+	frame    Frame
+	err      error
+}
+
+func (s *concatFrameSource) Header() Header {
+	if len(s.sources) == 0 {
+		return Header{Version: FormatVersion}
+	}
+
+	hdr := s.sources[0].Header()
+	for _, src := range s.sources[1:] {
+		srcHdr := src.Header()
+		if srcHdr.Width > hdr.Width {
+			hdr.Width = srcHdr.Width
+		}
+
+		if srcHdr.Height > hdr.Height {
+			hdr.Height = srcHdr.Height
+		}
+	}
+
+	return hdr
+}
+
+func (s *concatFrameSource) Next() bool {
+	for len(s.queue) == 0 {
+		if s.err != nil || s.index >= len(s.sources) {
+			return false
+		}
+
+		src := s.sources[s.index]
+		if src.Next() {
+			frame := src.Frame()
+			frame.Time = math.Max(frame.Time+s.offset, s.lastTime)
+			s.queue = append(s.queue, frame)
+
+			continue
+		}
+
+		if s.err = src.Err(); s.err != nil {
+			return false
+		}
+
+		s.index++
+		if s.index >= len(s.sources) {
+			return false
+		}
+
+		s.offset = s.lastTime + s.gap
+		s.queue = append(s.queue, Frame{Time: s.offset, Type: OutputFrame, Data: []byte(resetSequence)})
+
+		hdr, srcHdr := s.Header(), s.sources[s.index].Header()
+		if srcHdr.Width != hdr.Width || srcHdr.Height != hdr.Height {
+			s.queue = append(s.queue, Frame{Time: s.offset, Type: ResizeFrame, Data: ResizeData(srcHdr.Width, srcHdr.Height)})
+		}
+	}
+
+	s.frame, s.queue = s.queue[0], s.queue[1:]
+	s.lastTime = s.frame.Time
+
+	return true
+}
+
+func (s *concatFrameSource) Frame() Frame { return s.frame }
+
+func (s *concatFrameSource) Err() error { return s.err }
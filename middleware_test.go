@@ -0,0 +1,133 @@
+package player_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestFilter(t *testing.T) {
+	src := player.Filter(mustSourceFromString(t, editTestCast), func(frame player.Frame) bool {
+		return frame.Type != player.InputFrame && frame.Type != player.MarkerFrame
+	})
+
+	expected := []player.Frame{
+		{Time: 1, Type: player.OutputFrame, Data: []byte("a")},
+		{Time: 2, Type: player.OutputFrame, Data: []byte("b")},
+		{Time: 3, Type: player.ResizeFrame, Data: []byte("100x30")},
+		{Time: 3, Type: player.OutputFrame, Data: []byte("c")},
+		{Time: 5, Type: player.OutputFrame, Data: []byte("d")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestMap(t *testing.T) {
+	src := player.Map(mustSourceFromString(t, editTestCast), func(frame player.Frame) player.Frame {
+		if frame.Type == player.OutputFrame {
+			frame.Data = bytes.ToUpper(frame.Data)
+		}
+
+		return frame
+	})
+
+	frames := readFrames(t, src)
+	if len(frames) != 7 || string(frames[0].Data) != "A" || string(frames[1].Data) != "b" || string(frames[6].Data) != "D" {
+		t.Fatalf("Unexpected frames: %+v", frames)
+	}
+}
+
+func TestTimeShift(t *testing.T) {
+	src := player.TimeShift(mustSourceFromString(t, editTestCast), -2)
+
+	if hdr := src.Header(); hdr.Timestamp != 1002 {
+		t.Fatalf("Unexpected header: %+v", hdr)
+	}
+
+	expected := []float64{0, 0, 0, 1, 1, 2, 3}
+
+	if actual := frameTimes(readFrames(t, src)); !timesEqual(expected, actual) {
+		t.Fatalf("Unexpected times: %v", actual)
+	}
+}
+
+func TestTimeScale(t *testing.T) {
+	src := player.TimeScale(mustSourceFromString(t, editTestCast), 1.5)
+
+	expected := []float64{1.5, 3, 3, 4.5, 4.5, 6, 7.5}
+
+	if actual := frameTimes(readFrames(t, src)); !timesEqual(expected, actual) {
+		t.Fatalf("Unexpected times: %v", actual)
+	}
+}
+
+func TestClip(t *testing.T) {
+	src := player.Clip(mustSourceFromString(t, editTestCast), 2, 4)
+
+	if hdr := src.Header(); hdr.Timestamp != 1002 {
+		t.Fatalf("Unexpected header: %+v", hdr)
+	}
+
+	expected := []player.Frame{
+		{Time: 0, Type: player.InputFrame, Data: []byte("b")},
+		{Time: 0, Type: player.OutputFrame, Data: []byte("b")},
+		{Time: 1, Type: player.ResizeFrame, Data: []byte("100x30")},
+		{Time: 1, Type: player.OutputFrame, Data: []byte("c")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	src := player.Coalesce(mustSourceFromString(t, `{"version":2,"width":80,"height":24}
+[1,"o","a"]
+[1.01,"o","b"]
+[1.02,"i","c"]
+[1.03,"o","d"]
+[1.04,"o","e"]
+[1.2,"o","f"]
+[1.21,"r","100x30"]
+[1.22,"r","90x30"]
+`), 0.05)
+
+	expected := []player.Frame{
+		{Time: 1, Type: player.OutputFrame, Data: []byte("ab")},
+		{Time: 1.02, Type: player.InputFrame, Data: []byte("c")},
+		{Time: 1.03, Type: player.OutputFrame, Data: []byte("de")},
+		{Time: 1.2, Type: player.OutputFrame, Data: []byte("f")},
+		{Time: 1.21, Type: player.ResizeFrame, Data: []byte("100x30")},
+		{Time: 1.22, Type: player.ResizeFrame, Data: []byte("90x30")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
+
+func TestConcat(t *testing.T) {
+	src := player.Concat(0.5,
+		mustSourceFromString(t, `{"version":2,"width":80,"height":24}`+"\n"+`[1,"o","a"]`+"\n"),
+		mustSourceFromString(t, `{"version":2,"width":100,"height":20}`+"\n"+`[0.5,"o","b"]`+"\n"),
+	)
+
+	if hdr := src.Header(); hdr.Width != 100 || hdr.Height != 24 {
+		t.Fatalf("Unexpected header: %+v", hdr)
+	}
+
+	expected := []player.Frame{
+		{Time: 1, Type: player.OutputFrame, Data: []byte("a")},
+		{Time: 1.5, Type: player.OutputFrame, Data: []byte("\033c")},
+		{Time: 1.5, Type: player.ResizeFrame, Data: []byte("100x20")},
+		{Time: 2, Type: player.OutputFrame, Data: []byte("b")},
+	}
+
+	if actual := readFrames(t, src); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+}
@@ -8,6 +8,8 @@ type options struct {
 	maxWait         time.Duration
 	speed           float64
 	ignoreSizeCheck bool
+	startAt         float64
+	loop            bool
 }
 
 // Option for Player.
@@ -34,9 +36,27 @@ func WithSpeed(speed float64) Option {
 	}
 }
 
+// WithStartAt starts playback from given time in seconds: preceding output is written without delays,
+// so screen state is restored. Negative values are ignored.
+func WithStartAt(seconds float64) Option {
+	return func(o *options) {
+		if seconds > 0 {
+			o.startAt = seconds
+		}
+	}
+}
+
 //WithIgnoreSizeCheck turns off check that terminal can fit frames.
 func WithIgnoreSizeCheck() Option {
 	return func(o *options) {
 		o.ignoreSizeCheck = true
 	}
 }
+
+// WithLoop restarts playback from start position (see WithStartAt) when recording ends.
+// Last screen is shown for a second of recording time before restart.
+func WithLoop() Option {
+	return func(o *options) {
+		o.loop = true
+	}
+}
@@ -2,9 +2,15 @@ package player
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"sync"
 	"time"
 )
 
+// loopDelay is a time in seconds last screen is shown before playback restart, see WithLoop.
+const loopDelay = 1.
+
 var (
 	ErrUnexpectedVersion = fmt.Errorf("unexpected asciicast version")
 	ErrSmallTerminal     = fmt.Errorf("terminal too small for frames")
@@ -15,8 +21,24 @@ type Player struct {
 	terminal    Terminal
 	options     options
 
-	pause chan struct{}
-	stop  chan struct{}
+	pause    chan struct{}
+	seek     chan float64
+	speed    chan float64
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	// output frames read from source, they are replayed on backward seek;
+	// if source can be rewound only last frame is kept and source is read again instead
+	history  []Frame
+	next     int
+	rewinder Rewinder
+	played   bool // source has output frames
+
+	mu         sync.Mutex
+	position   float64   // time of last written frame or seek position
+	positionAt time.Time // when position was set, zero if paused
+	limit      float64   // time of next frame, position doesn't run ahead of it
 }
 
 func NewPlayer(frameSource FrameSource, terminal Terminal, opts ...Option) (*Player, error) {
@@ -45,7 +67,16 @@ func NewPlayer(frameSource FrameSource, terminal Terminal, opts ...Option) (*Pla
 		terminal:    terminal,
 		options:     defaultOptions,
 		pause:       make(chan struct{}),
+		seek:        make(chan float64),
+		speed:       make(chan float64),
 		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		position:    defaultOptions.startAt,
+		limit:       math.Inf(1),
+	}
+
+	if rewinder, ok := frameSource.(Rewinder); ok && rewinder.CanRewind() {
+		p.rewinder = rewinder
 	}
 
 	go terminal.Control(p)
@@ -55,6 +86,8 @@ func NewPlayer(frameSource FrameSource, terminal Terminal, opts ...Option) (*Pla
 
 // Start starts playback. Method blocks until Stop call.
 func (p *Player) Start() (err error) {
+	defer close(p.done)
+
 	if err = p.terminal.ToRaw(); err != nil {
 		return fmt.Errorf("put terminal to raw mode failed: %w", err)
 	}
@@ -68,39 +101,168 @@ func (p *Player) Start() (err error) {
 	timer := time.NewTimer(1)
 	<-timer.C // wait for first tick
 
-	prevFrameTime := 0.
+	paused := false
+	prevFrameTime := p.options.startAt
+	prevFrameWritten := time.Now()
+	p.setPosition(prevFrameTime, !paused)
+
+playback:
 	for {
-		if !p.frameSource.Next() {
-			return p.frameSource.Err()
+		frame, ok := p.nextFrame()
+		if !ok {
+			if err = p.frameSource.Err(); err != nil || !p.options.loop || !p.played {
+				return err
+			}
+
+			// screen is reset after a pause like frame following the last one
+			frame = Frame{Time: prevFrameTime + loopDelay, Type: OutputFrame, Data: []byte(resetSequence)}
 		}
 
-		frame := p.frameSource.Frame()
-		if frame.Type != OutputFrame {
+		if frame.Time < prevFrameTime {
+			// restore screen state before start or seek position
+			if _, err = p.terminal.Write(frame.Data); err != nil {
+				return fmt.Errorf("frame write failed: %w", err)
+			}
+
+			prevFrameWritten = time.Now()
+
 			continue
 		}
 
-		timer.Stop()
-		timer.Reset(p.nextFrameDelay(frame, prevFrameTime))
-		prevFrameTime = frame.Time
+		p.setLimit(frame.Time)
+
+		var pausedAfter time.Duration // time waited for frame before pause
+
+	wait:
+		for {
+			var timeout <-chan time.Time
+
+			if !paused {
+				// time spent waiting for frame (i.e. when source follows live recording) is a part of delay
+				timer.Reset(p.nextFrameDelay(frame, prevFrameTime) - time.Since(prevFrameWritten))
+				timeout = timer.C
+			}
 
-		select {
-		case <-timer.C:
-			// play
-		case <-p.pause:
 			select {
+			case <-timeout:
+				break wait
 			case <-p.pause:
-				// play
+				paused = !paused
+				if paused {
+					pausedAfter = time.Since(prevFrameWritten)
+				} else {
+					prevFrameWritten = time.Now().Add(-pausedAfter)
+				}
+
+				p.setPosition(p.Position(), !paused)
+			case speed := <-p.speed:
+				p.mu.Lock()
+				p.position, p.positionAt = p.positionLocked(), positionTime(!paused)
+				p.options.speed = speed
+				p.mu.Unlock()
+			case position := <-p.seek:
+				if ok {
+					p.next-- // frame will be read again
+				}
+
+				if position < p.Position() {
+					if err = p.rewind(); err != nil {
+						return err
+					}
+
+					if _, err = p.terminal.Write([]byte(resetSequence)); err != nil {
+						return fmt.Errorf("terminal reset failed: %w", err)
+					}
+				}
+
+				prevFrameTime, prevFrameWritten, pausedAfter = position, time.Now(), 0
+				p.setPosition(position, !paused)
+				p.setLimit(math.Inf(1))
+
+				if !timer.Stop() && timeout != nil {
+					<-timer.C
+				}
+
+				continue playback
 			case <-p.stop:
 				return nil
 			}
-		case <-p.stop:
-			return nil
+
+			if !timer.Stop() && timeout != nil {
+				<-timer.C
+			}
 		}
 
+		prevFrameTime = frame.Time
+
 		if _, err = p.terminal.Write(frame.Data); err != nil {
 			return fmt.Errorf("frame write failed: %w", err)
 		}
+
+		prevFrameWritten = time.Now()
+
+		if !ok {
+			// screen is restored up to start position without delays
+			if err = p.rewind(); err != nil {
+				return err
+			}
+
+			prevFrameTime = p.options.startAt
+			p.setPosition(prevFrameTime, !paused)
+			p.setLimit(math.Inf(1))
+
+			continue
+		}
+
+		p.setPosition(frame.Time, !paused)
+	}
+}
+
+// nextFrame returns next output frame from history or from source.
+func (p *Player) nextFrame() (Frame, bool) {
+	if p.next < len(p.history) {
+		p.next++
+		return p.history[p.next-1], true
+	}
+
+	for p.frameSource.Next() {
+		frame := p.frameSource.Frame()
+		if frame.Type != OutputFrame {
+			continue
+		}
+
+		frame.Data = append([]byte(nil), frame.Data...)
+
+		// last frame is kept anyway to be read again after seek
+		if p.rewinder != nil {
+			p.history = p.history[:0]
+		}
+
+		p.history = append(p.history, frame)
+		p.next = len(p.history)
+		p.played = true
+
+		return frame, true
+	}
+
+	return Frame{}, false
+}
+
+// rewind restarts reading of frames from beginning.
+func (p *Player) rewind() error {
+	p.next = 0
+
+	if p.rewinder == nil {
+		return nil
+	}
+
+	p.history = p.history[:0]
+
+	if err := p.rewinder.Rewind(); err != nil {
+		return fmt.Errorf("rewind failed: %w", err)
 	}
+
+	return nil
 }
 
 func (p *Player) nextFrameDelay(frame Frame, prevFrameTime float64) time.Duration {
@@ -112,14 +274,86 @@ func (p *Player) nextFrameDelay(frame Frame, prevFrameTime float64) time.Duratio
 	return delay
 }
 
-// Pause pauses playback. If playback already paused it will continue.
+func (p *Player) setPosition(position float64, playing bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.position, p.positionAt = position, positionTime(playing)
+}
+
+func (p *Player) setLimit(limit float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.limit = limit
+}
+
+func positionTime(playing bool) time.Time {
+	if playing {
+		return time.Now()
+	}
+
+	return time.Time{}
+}
+
+func (p *Player) positionLocked() float64 {
+	if p.positionAt.IsZero() {
+		return p.position
+	}
+
+	return math.Min(p.position+time.Since(p.positionAt).Seconds()*p.options.speed, math.Max(p.limit, p.position))
+}
+
+// Position returns current playback position in seconds.
+func (p *Player) Position() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.positionLocked()
+}
+
+// Pause pauses playback. If playback already paused it will continue. It does nothing after end of playback.
 func (p *Player) Pause() {
-	p.pause <- struct{}{}
+	select {
+	case p.pause <- struct{}{}:
+	case <-p.stop:
+	case <-p.done:
+	}
+}
+
+// Seek moves playback to given time in seconds. Output before it is written without delays.
+// On backward seek terminal is reset and output is replayed from beginning. Source implementing Rewinder is read again,
+// played output of other sources is kept in memory.
+func (p *Player) Seek(seconds float64) {
+	select {
+	case p.seek <- math.Max(seconds, 0):
+	case <-p.stop:
+	case <-p.done:
+	}
 }
 
-// Stop interrupts playback. Must be called once.
+// SetSpeed changes playback speed. Non-positive values are ignored.
+func (p *Player) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+
+	select {
+	case p.speed <- speed:
+	case <-p.stop:
+	case <-p.done:
+	}
+}
+
+// Stop interrupts playback. If frame source implements io.Closer it will be closed to interrupt pending Next call.
 func (p *Player) Stop() {
-	p.stop <- struct{}{}
+	p.stopOnce.Do(func() {
+		close(p.stop)
+
+		if closer, ok := p.frameSource.(io.Closer); ok {
+			closer.Close()
+		}
+	})
 }
 
 func (p *Player) sealed() {}
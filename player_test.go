@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
 )
 
 type bufferTerminal struct {
@@ -173,3 +177,242 @@ func TestPlayer_DisableSizeCheck(t *testing.T) {
 		t.Fatalf("Unexpected error returned: %s, expected nil", err)
 	}
 }
+
+func TestPlayer_StartAt(t *testing.T) {
+	source, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":80,"height":24}
+[1,"o","a"]
+[2,"o","b"]
+[3,"o","c"]
+[3.1,"o","d"]
+`))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	term := &bufferTerminal{Width: 80, Height: 24}
+
+	p, err := player.NewPlayer(source, term, player.WithStartAt(3))
+	if err != nil {
+		t.Fatalf("Player setup failed: %s", err)
+	}
+
+	start := time.Now()
+
+	if err = p.Start(); err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Frames before start position were delayed: %s", elapsed)
+	}
+
+	if term.String() != "abcd" {
+		t.Fatalf("Unexpected output: %q", term.String())
+	}
+}
+
+type controlTerminal struct {
+	writes  chan string
+	control chan player.PlaybackControl
+}
+
+func (c *controlTerminal) Write(p []byte) (int, error) {
+	c.writes <- string(p)
+	return len(p), nil
+}
+
+func (c *controlTerminal) Close() error { return nil }
+
+func (c *controlTerminal) Dimensions() (width, height int) { return 80, 24 }
+
+func (c *controlTerminal) ToRaw() error { return nil }
+
+func (c *controlTerminal) Restore() error { return nil }
+
+func (c *controlTerminal) Control(control player.PlaybackControl) { c.control <- control }
+
+func TestPlayer_SeekSpeed(t *testing.T) {
+	source, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":80,"height":24}
+[0.1,"o","a"]
+[0.2,"o","b"]
+[10,"o","c"]
+[20,"o","d"]
+`))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	term := &controlTerminal{writes: make(chan string, 100), control: make(chan player.PlaybackControl, 1)}
+
+	p, err := player.NewPlayer(source, term)
+	if err != nil {
+		t.Fatalf("Player setup failed: %s", err)
+	}
+
+	errCh := make(chan error, 1)
+	start := time.Now()
+
+	go func() { errCh <- p.Start() }()
+
+	control := <-term.control
+
+	var writes []string
+
+	read := func(n int) {
+		for i := 0; i < n; i++ {
+			writes = append(writes, <-term.writes)
+		}
+	}
+
+	read(2)
+	control.Seek(0.15) // backward: reset and replay
+	read(3)
+	control.SetSpeed(1000)
+	read(1)
+
+	if position := control.Position(); position < 10 || position > 20 {
+		t.Fatalf("Unexpected position %f", position)
+	}
+
+	control.Seek(20) // forward
+	read(1)
+
+	if err = <-errCh; err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Playback took too long: %s", elapsed)
+	}
+
+	expected := []string{"a", "b", "\033c", "a", "b", "c", "d"}
+	if strings.Join(expected, "|") != strings.Join(writes, "|") {
+		t.Fatalf("Unexpected writes: %q", writes)
+	}
+}
+
+func TestPlayer_Loop(t *testing.T) {
+	source, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":80,"height":24}
+[1,"o","a"]
+[2,"o","b"]
+[2.1,"o","c"]
+`))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	term := &controlTerminal{writes: make(chan string, 100), control: make(chan player.PlaybackControl, 1)}
+
+	p, err := player.NewPlayer(source, term, player.WithStartAt(2), player.WithSpeed(10), player.WithLoop())
+	if err != nil {
+		t.Fatalf("Player setup failed: %s", err)
+	}
+
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- p.Start() }()
+
+	control := <-term.control
+
+	// screen is reset and restored up to start position on every loop
+	var writes []string
+	for i := 0; i < 10; i++ {
+		writes = append(writes, <-term.writes)
+	}
+
+	control.Stop()
+
+	if err = <-errCh; err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+
+	expected := []string{"a", "b", "c", "\033c", "a", "b", "c", "\033c", "a", "b"}
+	if strings.Join(expected, "|") != strings.Join(writes, "|") {
+		t.Fatalf("Unexpected writes: %q", writes)
+	}
+}
+
+func TestPlayer_SeekBack(t *testing.T) {
+	var cast strings.Builder
+
+	cast.WriteString(`{"version":2,"width":80,"height":24}` + "\n")
+
+	// invisible padding makes long output
+	padding := strings.Repeat(`\u001b[m`, 250)
+	for i := 1; i <= 300; i++ {
+		fmt.Fprintf(&cast, "[%g,\"o\",\"%s\\r\\nline %03d\"]\n", float64(i)/100, padding, i)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		reader func() io.Reader
+	}{
+		{"rewindable", func() io.Reader { return strings.NewReader(cast.String()) }},
+		{"stream", func() io.Reader { return struct{ io.Reader }{strings.NewReader(cast.String())} }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := player.NewStreamFrameSource(tc.reader())
+			if err != nil {
+				t.Fatalf("Source create failed: %s", err)
+			}
+
+			term := &controlTerminal{writes: make(chan string, 100), control: make(chan player.PlaybackControl, 1)}
+
+			p, err := player.NewPlayer(source, term, player.WithSpeed(10))
+			if err != nil {
+				t.Fatalf("Player setup failed: %s", err)
+			}
+
+			errCh := make(chan error, 1)
+
+			go func() { errCh <- p.Start() }()
+
+			control := <-term.control
+
+			read := func() string {
+				select {
+				case write := <-term.writes:
+					return write
+				case <-time.After(5 * time.Second):
+					t.Fatalf("Write timed out")
+					return ""
+				}
+			}
+
+			for i := 0; i < 100; i++ {
+				read()
+			}
+
+			// output before seek position is replayed while paused
+			control.Pause()
+			control.Seek(0.505)
+
+			for write := read(); write != "\033c"; write = read() {
+			}
+
+			screen := vt.New(80, 24)
+			for write := ""; !strings.Contains(write, "line 050"); {
+				write = read()
+				screen.Write([]byte(write))
+			}
+
+			if screen.Line(22).String() != "line 049" || screen.Line(23).String() != "line 050" {
+				t.Fatalf("Unexpected screen:\n%s", screen.Text())
+			}
+
+			control.Stop()
+
+			for {
+				select {
+				case <-term.writes: // frames written before stop
+				case err = <-errCh:
+					if err != nil {
+						t.Fatalf("Play failed: %s", err)
+					}
+
+					return
+				}
+			}
+		})
+	}
+}
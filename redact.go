@@ -0,0 +1,389 @@
+package player
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/xakep666/asciinema-player/v3/internal/ansi"
+)
+
+// redactWindow is a maximal length of secret in bytes. Frames are held back until this amount of data follows them,
+// so secret interleaved with frames of other type must fit into it too.
+const redactWindow = 16 << 10
+
+// redactMask replaces every character of secret.
+const redactMask = '*'
+
+// secretGroup is a name of regexp group that should be redacted instead of whole match.
+const secretGroup = "secret"
+
+// RedactRule describes secret to redact.
+type RedactRule struct {
+	// Name of rule used in report.
+	Name string
+
+	// Pattern matches secret. If it contains group named "secret" only this group is redacted.
+	Pattern *regexp.Regexp
+}
+
+// LiteralRule constructs RedactRule matching literal string.
+func LiteralRule(name, literal string) RedactRule {
+	return RedactRule{Name: name, Pattern: regexp.MustCompile(regexp.QuoteMeta(literal))}
+}
+
+// RegexpRule constructs RedactRule matching regular expression.
+func RegexpRule(name, expr string) (RedactRule, error) {
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return RedactRule{}, fmt.Errorf("rule %q: %w", name, err)
+	}
+
+	return RedactRule{Name: name, Pattern: pattern}, nil
+}
+
+// DefaultRedactRules returns built-in detectors of common secrets: AWS keys, JWTs, PEM private keys and some API tokens.
+func DefaultRedactRules() []RedactRule {
+	return []RedactRule{
+		{Name: "aws-access-key-id", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "aws-secret-access-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})`)},
+		{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`)},
+		{Name: "pem-private-key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+		{Name: "github-token", Pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	}
+}
+
+// Redaction is a report about redacted secret. It doesn't contain secret itself.
+type Redaction struct {
+	// Rule is a name of matched rule.
+	Rule string
+
+	// Type of frame where secret begins.
+	Type FrameType
+
+	// Time of frame where secret begins.
+	Time float64
+
+	// Length is a number of redacted characters.
+	Length int
+}
+
+// RedactFrameSource replaces secrets in output, input and marker frames with "*". Character count is kept so screen layout is preserved.
+// Secrets are matched against text visible in terminal so they are found even if split between frames or interleaved with escape sequences.
+// Window titles and other operating system commands are checked too.
+type RedactFrameSource struct {
+	src   FrameSource
+	rules []RedactRule
+
+	queue      []*redactEntry
+	frame      Frame
+	redactions []Redaction
+	done       bool
+}
+
+type redactEntry struct {
+	frame Frame
+	final bool
+}
+
+// NewRedactFrameSource constructs RedactFrameSource.
+func NewRedactFrameSource(src FrameSource, rules ...RedactRule) *RedactFrameSource {
+	return &RedactFrameSource{
+		src:   src,
+		rules: rules,
+	}
+}
+
+// Redactions returns report about secrets redacted so far.
+func (s *RedactFrameSource) Redactions() []Redaction { return s.redactions }
+
+// Header returns asciinema-v2 header.
+func (s *RedactFrameSource) Header() Header { return s.src.Header() }
+
+// Next advances to next available frame. It must return false if error occurs or there is no more frames.
+func (s *RedactFrameSource) Next() bool {
+	for len(s.queue) == 0 || !s.queue[0].final {
+		if s.done {
+			return false
+		}
+
+		if !s.src.Next() {
+			if s.src.Err() != nil {
+				return false
+			}
+
+			s.done = true
+			s.scan(true)
+
+			continue
+		}
+
+		frame := s.src.Frame()
+		frame.Data = append([]byte(nil), frame.Data...)
+
+		switch frame.Type {
+		case OutputFrame, InputFrame:
+			s.queue = append(s.queue, &redactEntry{frame: frame})
+			s.scan(false)
+		case MarkerFrame:
+			entry := &redactEntry{frame: frame, final: true}
+			s.redact(MarkerFrame, []*redactEntry{entry}, len(frame.Data))
+			s.queue = append(s.queue, entry)
+		default:
+			s.queue = append(s.queue, &redactEntry{frame: frame, final: true})
+		}
+	}
+
+	s.frame = s.queue[0].frame
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+
+	return true
+}
+
+// Frame returns current frame. It becomes unusable after Next call.
+func (s *RedactFrameSource) Frame() Frame { return s.frame }
+
+// Err returns error if it happens during iteration.
+func (s *RedactFrameSource) Err() error { return s.src.Err() }
+
+// scan finds secrets in pending frames and finalizes frames followed by enough data of any type,
+// so pending frames of one type don't hold back frames of another one.
+func (s *RedactFrameSource) scan(flush bool) {
+	var (
+		pending []*redactEntry
+		size    int
+	)
+
+	for _, entry := range s.queue {
+		if !entry.final {
+			pending = append(pending, entry)
+			size += len(entry.frame.Data)
+		}
+	}
+
+	// scan is performed when enough data accumulated to finalize many frames at once
+	if !flush && size < 2*redactWindow {
+		return
+	}
+
+	// frames are finalized while secret beginning in them can't continue beyond pending data
+	finalize, boundary := 0, 0
+	for _, entry := range pending {
+		if !flush && size-boundary-len(entry.frame.Data) < redactWindow {
+			break
+		}
+
+		finalize++
+		boundary += len(entry.frame.Data)
+	}
+
+	if finalize == 0 {
+		return
+	}
+
+	for _, frameType := range []FrameType{OutputFrame, InputFrame} {
+		var (
+			entries      []*redactEntry
+			typeBoundary int
+		)
+
+		for i, entry := range pending {
+			if entry.frame.Type != frameType {
+				continue
+			}
+
+			entries = append(entries, entry)
+			if i < finalize {
+				typeBoundary += len(entry.frame.Data)
+			}
+		}
+
+		if typeBoundary > 0 {
+			s.redact(frameType, entries, typeBoundary)
+		}
+	}
+
+	for i, entry := range pending {
+		entry.final = i < finalize
+	}
+}
+
+// redact masks secrets in data of entries joined together. Secrets beginning at or after boundary are skipped,
+// they are redacted when entries containing them are scanned again.
+func (s *RedactFrameSource) redact(frameType FrameType, entries []*redactEntry, boundary int) {
+	size := 0
+	for _, entry := range entries {
+		size += len(entry.frame.Data)
+	}
+
+	buf := make([]byte, 0, size)
+	for _, entry := range entries {
+		buf = append(buf, entry.frame.Data...)
+	}
+
+	text := newVisibleText(buf)
+	masked := make([]bool, len(buf))
+	anyMasked := false
+	reported := len(s.redactions)
+
+	for _, rule := range s.rules {
+		group := rule.Pattern.SubexpIndex(secretGroup)
+
+		for _, match := range rule.Pattern.FindAllSubmatchIndex(text.text, -1) {
+			start, end := match[0], match[1]
+			if group > 0 && match[2*group] >= 0 {
+				start, end = match[2*group], match[2*group+1]
+			}
+
+			if start == end {
+				continue
+			}
+
+			// whole match is checked since frames with its beginning won't be scanned again
+			if text.rawOffset[match[0]] >= boundary {
+				continue // will be processed with frames where it begins
+			}
+
+			length := text.mask(start, end, masked)
+			if length == 0 {
+				continue
+			}
+
+			anyMasked = true
+
+			entry := entries[0]
+			for i, offset := 1, len(entries[0].frame.Data); i < len(entries) && offset <= text.rawOffset[start]; i++ {
+				entry = entries[i]
+				offset += len(entry.frame.Data)
+			}
+
+			s.redactions = append(s.redactions, Redaction{
+				Rule:   rule.Name,
+				Type:   frameType,
+				Time:   entry.frame.Time,
+				Length: length,
+			})
+		}
+	}
+
+	newRedactions := s.redactions[reported:]
+	sort.SliceStable(newRedactions, func(i, j int) bool { return newRedactions[i].Time < newRedactions[j].Time })
+
+	if !anyMasked {
+		return
+	}
+
+	offset := 0
+	for _, entry := range entries {
+		data := entry.frame.Data
+		entry.frame.Data = applyMask(data, masked[offset:offset+len(data)])
+		offset += len(data)
+	}
+}
+
+// applyMask replaces characters which first byte is marked with mask character.
+func applyMask(data []byte, masked []bool) []byte {
+	var (
+		result  []byte
+		skipped int
+	)
+
+	for i := 0; i < len(data); {
+		if !masked[i] {
+			i++
+			continue
+		}
+
+		if result == nil {
+			result = make([]byte, 0, len(data))
+			result = append(result, data[:i]...)
+		} else {
+			result = append(result, data[skipped:i]...)
+		}
+
+		_, size := utf8.DecodeRune(data[i:])
+		result = append(result, redactMask)
+		i += size
+		skipped = i
+	}
+
+	if result == nil {
+		return data
+	}
+
+	return append(result, data[skipped:]...)
+}
+
+// visibleText is a text visible in terminal (printed characters, line breaks and tabs) and contents of operating system commands.
+type visibleText struct {
+	text []byte
+
+	// rawOffset contains position in raw data for every byte of text.
+	rawOffset []int
+
+	// maskable is true for bytes of text that may be replaced with mask.
+	maskable []bool
+}
+
+func newVisibleText(raw []byte) *visibleText {
+	var (
+		parser ansi.Parser
+		t      = &visibleText{
+			text:      make([]byte, 0, len(raw)),
+			rawOffset: make([]int, 0, len(raw)),
+			maskable:  make([]bool, 0, len(raw)),
+		}
+	)
+
+	parser.Feed(raw, func(seq *ansi.Sequence) {
+		offset := int(seq.Offset)
+
+		switch seq.Kind {
+		case ansi.Print:
+			for i, b := range seq.Raw {
+				t.append(b, offset+i, i == 0)
+			}
+		case ansi.Control:
+			switch seq.Byte {
+			case '\r', '\n', '\t':
+				t.append(seq.Byte, offset, false)
+			}
+		case ansi.OSC:
+			// data begins after "ESC ]", control characters are not a part of data
+			for i := offset + 2; i < offset+len(seq.Raw) && raw[i] != 0x07 && raw[i] != 0x1b; i++ {
+				if raw[i] >= 0x20 {
+					t.append(raw[i], i, utf8.RuneStart(raw[i]))
+				}
+			}
+
+			t.append('\n', offset, false) // separate from following text
+		}
+	})
+
+	return t
+}
+
+func (t *visibleText) append(b byte, rawOffset int, maskable bool) {
+	t.text = append(t.text, b)
+	t.rawOffset = append(t.rawOffset, rawOffset)
+	t.maskable = append(t.maskable, maskable)
+}
+
+// mask marks raw characters for text range, it returns number of marked characters.
+func (t *visibleText) mask(start, end int, masked []bool) int {
+	count := 0
+
+	for i := start; i < end; i++ {
+		if !t.maskable[i] || masked[t.rawOffset[i]] {
+			continue
+		}
+
+		masked[t.rawOffset[i]] = true
+		count++
+	}
+
+	return count
+}
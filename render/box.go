@@ -0,0 +1,97 @@
+package render
+
+import "image"
+
+// Line segments of box drawing character going from center of cell.
+const (
+	up = 1 << iota
+	down
+	left
+	right
+	heavy // lines are 2 pixels thick, double lines are drawn as heavy
+)
+
+// boxLines are common box drawing characters: light, heavy, double and rounded lines with corners, tees and crosses.
+var boxLines = map[rune]uint8{
+	'─': left | right, '│': up | down, '┌': down | right, '┐': down | left, '└': up | right, '┘': up | left,
+	'├': up | down | right, '┤': up | down | left, '┬': down | left | right, '┴': up | left | right, '┼': up | down | left | right,
+	'╭': down | right, '╮': down | left, '╯': up | left, '╰': up | right, '╴': left, '╵': up, '╶': right, '╷': down,
+
+	'━': heavy | left | right, '┃': heavy | up | down, '┏': heavy | down | right, '┓': heavy | down | left,
+	'┗': heavy | up | right, '┛': heavy | up | left, '┣': heavy | up | down | right, '┫': heavy | up | down | left,
+	'┳': heavy | down | left | right, '┻': heavy | up | left | right, '╋': heavy | up | down | left | right,
+
+	'═': heavy | left | right, '║': heavy | up | down, '╔': heavy | down | right, '╗': heavy | down | left,
+	'╚': heavy | up | right, '╝': heavy | up | left, '╠': heavy | up | down | right, '╣': heavy | up | down | left,
+	'╦': heavy | down | left | right, '╩': heavy | up | left | right, '╬': heavy | up | down | left | right,
+}
+
+// drawBox draws box drawing and block element characters missing in built-in font so borders of TUI apps are
+// continuous. It returns false for other characters.
+func drawBox(img *image.Paletted, rect image.Rectangle, r rune, index uint8) bool {
+	if lines, ok := boxLines[r]; ok {
+		drawLines(img, rect, lines, index)
+		return true
+	}
+
+	width, height := rect.Dx(), rect.Dy()
+
+	switch {
+	case r == '█':
+		fill(img, rect, index)
+	case r == '▀':
+		fill(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+height/2), index)
+	case r >= '▁' && r <= '▇': // lower eighths
+		fill(img, image.Rect(rect.Min.X, rect.Max.Y-height*int(r-'▁'+1)/8, rect.Max.X, rect.Max.Y), index)
+	case r >= '▉' && r <= '▏': // left eighths
+		fill(img, image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+width*int('▏'-r+1)/8, rect.Max.Y), index)
+	case r == '▐':
+		fill(img, image.Rect(rect.Min.X+width/2, rect.Min.Y, rect.Max.X, rect.Max.Y), index)
+	case r >= '░' && r <= '▓': // shades are dither patterns of growing density
+		density := int(r - '░' + 1)
+
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				if shade(x, y) < density {
+					img.Pix[img.PixOffset(x, y)] = index
+				}
+			}
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// shade returns 0-3 for pixel so that pixels with values below n cover n quarters of area.
+func shade(x, y int) int {
+	return [2][2]int{{0, 2}, {3, 1}}[y%2][x%2]
+}
+
+func drawLines(img *image.Paletted, rect image.Rectangle, lines uint8, index uint8) {
+	thickness := 1
+	if lines&heavy != 0 {
+		thickness = 2
+	}
+
+	cx, cy := rect.Min.X+rect.Dx()/2, rect.Min.Y+rect.Dy()/2
+	x0, y0 := cx-thickness/2, cy-thickness/2
+	x1, y1 := x0+thickness, y0+thickness
+
+	if lines&up != 0 {
+		fill(img, image.Rect(x0, rect.Min.Y, x1, y1), index)
+	}
+
+	if lines&down != 0 {
+		fill(img, image.Rect(x0, y0, x1, rect.Max.Y), index)
+	}
+
+	if lines&left != 0 {
+		fill(img, image.Rect(rect.Min.X, y0, x1, y1), index)
+	}
+
+	if lines&right != 0 {
+		fill(img, image.Rect(x0, y0, rect.Max.X, y1), index)
+	}
+}
@@ -0,0 +1,170 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/gif"
+	"io"
+	"math"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// DefaultFrameRate is a frame rate of GIF used if not set.
+const DefaultFrameRate = 10
+
+// lastFrameDelay is a delay of last GIF frame in 1/100 of second if recording has nothing after it.
+const lastFrameDelay = 100
+
+// GIF plays recording and encodes it as looped animated GIF with given maximal frame rate (frames per second).
+// Changes of screen within one frame are merged, GIF frames contain only changed part of screen.
+// Timing is taken from recording as is, use player.ChangeSpeed, player.LimitIdle and player.HoldEnd to adjust it.
+// ErrTooLarge or ErrTooManyFrames is returned if animation exceeds limits of rendering.
+func GIF(w io.Writer, src player.FrameSource, palette Palette, frameRate int) error {
+	if frameRate <= 0 {
+		frameRate = DefaultFrameRate
+	}
+
+	hdr := src.Header()
+
+	screen, err := newScreen(hdr)
+	if err != nil {
+		return err
+	}
+
+	enc := &gifEncoder{bounds: image.Rect(0, 0, hdr.Width*CellWidth, hdr.Height*CellHeight)}
+
+	slot := func(t float64) int { return int(math.Floor(t * float64(frameRate))) }
+
+	var (
+		dirty bool
+		last  float64
+	)
+
+	for src.Next() {
+		frame := src.Frame()
+
+		// screen is captured when all frames at the same time are applied
+		if dirty && frame.Time > last {
+			if err = enc.add(Image(screen, palette), slot(last)); err != nil {
+				return err
+			}
+
+			dirty = false
+		}
+
+		if frame.Type == player.OutputFrame || frame.Type == player.ResizeFrame {
+			if err = applyFrame(screen, frame); err != nil {
+				return err
+			}
+
+			dirty = true
+		}
+
+		last = frame.Time
+	}
+
+	if err = src.Err(); err != nil {
+		return err
+	}
+
+	if dirty || len(enc.anim.Image) == 0 {
+		if err = enc.add(Image(screen, palette), slot(last)); err != nil {
+			return err
+		}
+	}
+
+	enc.finish(slot(last), 100/float64(frameRate))
+
+	return gif.EncodeAll(w, &enc.anim)
+}
+
+// gifEncoder collects GIF frames, every frame contains only part of image changed since previous one.
+type gifEncoder struct {
+	anim   gif.GIF
+	bounds image.Rectangle // of GIF, screen may be resized during playback
+	slots  []int           // frame slots of added images
+	pixels int             // total size of frames
+
+	shown   *image.Paletted // image shown before last added one
+	current *image.Paletted // image shown after last added one
+}
+
+// add adds image shown since given frame slot. Image replaces last added one in the same slot.
+func (e *gifEncoder) add(img *image.Paletted, slot int) error {
+	if n := len(e.slots); n > 0 && e.slots[n-1] == slot {
+		e.pixels -= e.anim.Image[n-1].Rect.Dx() * e.anim.Image[n-1].Rect.Dy()
+		e.anim.Image, e.anim.Delay, e.anim.Disposal = e.anim.Image[:n-1], e.anim.Delay[:n-1], e.anim.Disposal[:n-1]
+		e.slots = e.slots[:n-1]
+		e.current = e.shown
+	}
+
+	changed := e.bounds
+	if e.current != nil {
+		changed = diff(e.current, img).Intersect(e.bounds)
+		if changed.Empty() {
+			return nil
+		}
+	}
+
+	if len(e.anim.Image) >= MaxGIFFrames {
+		return fmt.Errorf("%w: %d frames is maximal", ErrTooManyFrames, MaxGIFFrames)
+	}
+
+	if e.pixels += changed.Dx() * changed.Dy(); e.pixels > MaxGIFPixels {
+		return fmt.Errorf("%w: frames exceed %d pixels", ErrTooLarge, MaxGIFPixels)
+	}
+
+	frame := image.NewPaletted(changed, img.Palette)
+	for y := changed.Min.Y; y < changed.Max.Y; y++ {
+		for x := changed.Min.X; x < changed.Max.X; x++ {
+			if (image.Point{X: x, Y: y}).In(img.Rect) {
+				frame.Pix[frame.PixOffset(x, y)] = img.Pix[img.PixOffset(x, y)]
+			}
+		}
+	}
+
+	e.anim.Image = append(e.anim.Image, frame)
+	e.anim.Delay = append(e.anim.Delay, 0)
+	e.anim.Disposal = append(e.anim.Disposal, gif.DisposalNone)
+	e.slots = append(e.slots, slot)
+
+	e.shown, e.current = e.current, img
+
+	return nil
+}
+
+// finish sets delays of frames, last frame is shown until end slot.
+func (e *gifEncoder) finish(end int, slotDelay float64) {
+	for i := range e.anim.Delay {
+		next := end
+		if i+1 < len(e.slots) {
+			next = e.slots[i+1]
+		}
+
+		e.anim.Delay[i] = int(math.Round(float64(next-e.slots[i]) * slotDelay))
+	}
+
+	if n := len(e.anim.Delay); n > 0 && e.anim.Delay[n-1] == 0 {
+		e.anim.Delay[n-1] = lastFrameDelay
+	}
+}
+
+// diff returns bounding rectangle of pixels differing between images.
+func diff(a, b *image.Paletted) image.Rectangle {
+	if a.Rect != b.Rect {
+		return a.Rect.Union(b.Rect)
+	}
+
+	changed := image.Rectangle{}
+
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+			if a.Palette[a.Pix[a.PixOffset(x, y)]] != b.Palette[b.Pix[b.PixOffset(x, y)]] {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	return changed
+}
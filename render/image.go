@@ -0,0 +1,223 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// Cell size of raster images in pixels, it's a glyph size of built-in bitmap font.
+const (
+	CellWidth  = 7
+	CellHeight = 13
+)
+
+// Limits of rendering, recordings beyond them are rejected so they can't exhaust memory.
+const (
+	// MaxWidth and MaxHeight limit screen size in cells.
+	MaxWidth  = 500
+	MaxHeight = 200
+
+	// MaxGIFFrames limits number of GIF frames.
+	MaxGIFFrames = 5000
+
+	// MaxGIFPixels limits total size of GIF frames before encoding.
+	MaxGIFPixels = 1 << 28
+)
+
+var (
+	ErrTooLarge      = fmt.Errorf("recording is too large to render")
+	ErrTooManyFrames = fmt.Errorf("recording has too many frames to render")
+)
+
+// face is a built-in font. It has only ASCII characters, others are drawn as replacement character
+// except box drawing and block elements drawn by drawBox.
+var face = basicfont.Face7x13
+
+// Image draws screen as raster image with built-in bitmap font. Cursor is not drawn.
+//
+// Palette of image is made of colors used on screen, so image is exact unless there are more than 256 of them.
+// Other colors are replaced by nearest ones.
+func Image(screen *vt.Screen, palette Palette) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, screen.Width()*CellWidth, screen.Height()*CellHeight), nil)
+	colors := &colorTable{indexes: make(map[color.RGBA]uint8)}
+
+	bg := colors.index(palette.BG) // zero index fills new image
+
+	for y, line := range screen.Lines() {
+		for x, cell := range line.Cells {
+			if cell.Width == 0 {
+				continue // drawn with previous wide character
+			}
+
+			rect := image.Rect(x*CellWidth, y*CellHeight, (x+cell.Width)*CellWidth, (y+1)*CellHeight).Intersect(img.Rect)
+
+			if cellBG := colors.index(palette.Color(cell.Attr, false)); cellBG != bg {
+				fill(img, rect, cellBG)
+			}
+
+			if cell.Attr.Flags&vt.Hidden != 0 {
+				continue
+			}
+
+			fg := palette.Color(cell.Attr, true)
+			if cell.Attr.Flags&vt.Faint != 0 {
+				fg = blend(fg, palette.Color(cell.Attr, false), 0.6)
+			}
+
+			drawCell(img, rect, cell, colors.index(fg))
+		}
+	}
+
+	img.Palette = colors.palette
+
+	return img
+}
+
+// PNG draws screen as PNG image.
+func PNG(w io.Writer, screen *vt.Screen, palette Palette) error {
+	return png.Encode(w, Image(screen, palette))
+}
+
+// ScreenAt plays recording into virtual terminal until given time (in seconds) and returns its screen.
+// Infinite time gives final screen. ErrTooLarge is returned if screen exceeds MaxWidth or MaxHeight.
+func ScreenAt(src player.FrameSource, t float64) (*vt.Screen, error) {
+	screen, err := newScreen(src.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	for src.Next() {
+		frame := src.Frame()
+		if frame.Time > t {
+			break
+		}
+
+		if err = applyFrame(screen, frame); err != nil {
+			return nil, err
+		}
+	}
+
+	return screen, src.Err()
+}
+
+// newScreen creates virtual terminal of recording size.
+func newScreen(hdr player.Header) (*vt.Screen, error) {
+	if err := checkSize(hdr.Width, hdr.Height); err != nil {
+		return nil, err
+	}
+
+	return vt.New(hdr.Width, hdr.Height), nil
+}
+
+// applyFrame applies output and resize frames to screen.
+func applyFrame(screen *vt.Screen, frame player.Frame) error {
+	switch frame.Type {
+	case player.OutputFrame:
+		screen.Write(frame.Data)
+	case player.ResizeFrame:
+		if width, height, err := player.ParseResizeData(frame.Data); err == nil {
+			if err = checkSize(width, height); err != nil {
+				return err
+			}
+
+			screen.Resize(width, height)
+		}
+	}
+
+	return nil
+}
+
+func checkSize(width, height int) error {
+	if width > MaxWidth || height > MaxHeight {
+		return fmt.Errorf("%w: %dx%d screen, %dx%d is maximal", ErrTooLarge, width, height, MaxWidth, MaxHeight)
+	}
+
+	return nil
+}
+
+// colorTable builds palette of image, colors beyond 256 are mapped to nearest ones.
+type colorTable struct {
+	palette color.Palette
+	indexes map[color.RGBA]uint8
+}
+
+func (t *colorTable) index(c color.RGBA) uint8 {
+	if i, ok := t.indexes[c]; ok {
+		return i
+	}
+
+	var i uint8
+	if len(t.palette) < 256 {
+		i = uint8(len(t.palette))
+		t.palette = append(t.palette, c)
+	} else {
+		i = uint8(t.palette.Index(c))
+	}
+
+	t.indexes[c] = i
+
+	return i
+}
+
+func blend(fg, bg color.RGBA, alpha float64) color.RGBA {
+	mix := func(a, b uint8) uint8 { return uint8(math.Round(float64(a)*alpha + float64(b)*(1-alpha))) }
+
+	return color.RGBA{R: mix(fg.R, bg.R), G: mix(fg.G, bg.G), B: mix(fg.B, bg.B), A: 0xff}
+}
+
+func fill(img *image.Paletted, rect image.Rectangle, index uint8) {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(rect.Min.X, y):img.PixOffset(rect.Max.X, y)]
+		for i := range row {
+			row[i] = index
+		}
+	}
+}
+
+// drawCell draws character of cell with its decorations.
+func drawCell(img *image.Paletted, rect image.Rectangle, cell vt.Cell, index uint8) {
+	if cell.Rune != ' ' && !drawBox(img, rect, cell.Rune, index) {
+		drawGlyph(img, rect, cell.Rune, index)
+
+		// bold text is emboldened by overprinting shifted glyph
+		if cell.Attr.Flags&vt.Bold != 0 {
+			drawGlyph(img, rect.Add(image.Pt(1, 0)).Intersect(img.Rect), cell.Rune, index)
+		}
+	}
+
+	if cell.Attr.Flags&vt.Underline != 0 {
+		fill(img, image.Rect(rect.Min.X, rect.Max.Y-1, rect.Max.X, rect.Max.Y), index)
+	}
+
+	if cell.Attr.Flags&vt.Strikethrough != 0 {
+		middle := rect.Min.Y + CellHeight/2
+		fill(img, image.Rect(rect.Min.X, middle, rect.Max.X, middle+1), index)
+	}
+}
+
+func drawGlyph(img *image.Paletted, rect image.Rectangle, r rune, index uint8) {
+	dr, mask, maskp, _, ok := face.Glyph(fixed.P(rect.Min.X, rect.Min.Y+face.Ascent), r)
+	if !ok {
+		return
+	}
+
+	clip := dr.Intersect(rect)
+
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		for x := clip.Min.X; x < clip.Max.X; x++ {
+			if _, _, _, a := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA(); a >= 0x8000 {
+				img.Pix[img.PixOffset(x, y)] = index
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// countColor counts pixels of color in rectangle.
+func countColor(img *image.Paletted, rect image.Rectangle, c color.RGBA) int {
+	var n int
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if img.At(x, y) == c {
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+func cellRect(x, y int) image.Rectangle {
+	return image.Rect(x*CellWidth, y*CellHeight, (x+1)*CellWidth, (y+1)*CellHeight)
+}
+
+func TestImage(t *testing.T) {
+	screen := vt.New(4, 2)
+	screen.Write([]byte("\x1b[31;44mA\x1b[0m\x1b[8mB\x1b[0m─\r\n█"))
+
+	palette := DefaultPalette()
+	img := Image(screen, palette)
+
+	if img.Rect != image.Rect(0, 0, 4*CellWidth, 2*CellHeight) || len(img.Palette) != 4 {
+		t.Fatalf("Unexpected image %v with %d colors", img.Rect, len(img.Palette))
+	}
+
+	// glyph is drawn over cell background
+	if red, blue := countColor(img, cellRect(0, 0), palette.Colors[1]), countColor(img, cellRect(0, 0), palette.Colors[4]); red == 0 || red+blue != CellWidth*CellHeight {
+		t.Fatalf("Unexpected cell with %d glyph and %d background pixels", red, blue)
+	}
+
+	// hidden text isn't drawn, box drawing line is a horizontal line, block fills cell
+	for _, tc := range []struct {
+		x, y, pixels int
+	}{
+		{1, 0, 0},
+		{2, 0, CellWidth},
+		{0, 1, CellWidth * CellHeight},
+	} {
+		if n := countColor(img, cellRect(tc.x, tc.y), palette.FG); n != tc.pixels {
+			t.Fatalf("Cell %d,%d has %d pixels of text, %d expected", tc.x, tc.y, n, tc.pixels)
+		}
+	}
+}
+
+func TestGIF(t *testing.T) {
+	src, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":10,"height":2}
+[0.5,"o","a"]
+[0.52,"o","b"]
+[1.5,"i","x"]
+[2.0,"o","\r\nc"]
+`))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err = GIF(&buf, player.HoldEnd(src, 3), DefaultPalette(), 10); err != nil {
+		t.Fatalf("GIF failed: %s", err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("Invalid GIF: %s", err)
+	}
+
+	// changes within frame are merged, input doesn't change screen, last frame is held
+	if len(anim.Image) != 2 || anim.Delay[0] != 150 || anim.Delay[1] != 300 {
+		t.Fatalf("Unexpected %d frames with delays %v", len(anim.Image), anim.Delay)
+	}
+
+	// first frame is full, second one contains only changed cell
+	if anim.Image[0].Rect != image.Rect(0, 0, 10*CellWidth, 2*CellHeight) || !anim.Image[1].Rect.In(cellRect(0, 1)) {
+		t.Fatalf("Unexpected frame bounds %v %v", anim.Image[0].Rect, anim.Image[1].Rect)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	src, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":1000,"height":2}` + "\n"))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if _, err = ScreenAt(src, math.Inf(1)); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Unexpected error for large screen: %v", err)
+	}
+
+	if src, err = player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":10,"height":2}
+[1,"r","10x1000"]
+`)); err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if err = GIF(io.Discard, src, DefaultPalette(), 10); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Unexpected error for large resize: %v", err)
+	}
+
+	cast := `{"version":2,"width":10,"height":2}` + "\n"
+	for i := 0; i <= MaxGIFFrames; i++ {
+		cast += fmt.Sprintf("[%d,\"o\",\"%d\\r\"]\n", i, i%10)
+	}
+
+	if src, err = player.NewStreamFrameSource(strings.NewReader(cast)); err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if err = GIF(io.Discard, src, DefaultPalette(), 10); !errors.Is(err, ErrTooManyFrames) {
+		t.Fatalf("Unexpected error for long animation: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package render draws screen of virtual terminal (package vt) as image.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// Palette is a set of terminal colors.
+type Palette struct {
+	// FG and BG are default foreground and background colors.
+	FG, BG color.RGBA
+
+	// Colors is a 256-color palette, first 16 colors are standard and bright ones.
+	Colors [256]color.RGBA
+}
+
+var standardColors = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// DefaultPalette returns xterm colors with light gray text on black background.
+func DefaultPalette() Palette {
+	p := Palette{FG: color.RGBA{R: 0xcc, G: 0xcc, B: 0xcc, A: 0xff}, BG: color.RGBA{A: 0xff}}
+
+	for i, hex := range standardColors {
+		p.Colors[i], _ = parseHex(hex)
+	}
+
+	levels := [6]uint8{0, 95, 135, 175, 215, 255}
+	for c := 0; c < 216; c++ {
+		p.Colors[16+c] = color.RGBA{R: levels[c/36], G: levels[c/6%6], B: levels[c%6], A: 0xff}
+	}
+
+	for g := 0; g < 24; g++ {
+		level := uint8(8 + g*10)
+		p.Colors[232+g] = color.RGBA{R: level, G: level, B: level, A: 0xff}
+	}
+
+	return p
+}
+
+// PaletteFromTheme returns DefaultPalette with colors of theme from recording header. Nil theme gives DefaultPalette.
+// Palette of 8 colors is repeated for bright colors.
+func PaletteFromTheme(theme *player.Theme) (Palette, error) {
+	p := DefaultPalette()
+	if theme == nil {
+		return p, nil
+	}
+
+	var err error
+
+	if theme.FG != "" {
+		if p.FG, err = parseHex(theme.FG); err != nil {
+			return p, err
+		}
+	}
+
+	if theme.BG != "" {
+		if p.BG, err = parseHex(theme.BG); err != nil {
+			return p, err
+		}
+	}
+
+	if theme.Palette != "" {
+		colors := strings.Split(theme.Palette, ":")
+
+		for i := 0; i < 16; i++ {
+			if p.Colors[i], err = parseHex(colors[i%len(colors)]); err != nil {
+				return p, err
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// Color returns foreground or background color of cell attributes.
+// Inverse attribute is taken into account, bold text of standard color is drawn with bright one.
+func (p *Palette) Color(attr vt.Attr, fg bool) color.RGBA {
+	if attr.Flags&vt.Inverse != 0 {
+		fg = !fg
+	}
+
+	c, def := attr.BG, p.BG
+	if fg {
+		c, def = attr.FG, p.FG
+	}
+
+	if index, ok := c.Index(); ok {
+		if fg && attr.Flags&vt.Bold != 0 && index < 8 {
+			index += 8
+		}
+
+		return p.Colors[index]
+	}
+
+	if r, g, b, ok := c.RGB(); ok {
+		return color.RGBA{R: r, G: g, B: b, A: 0xff}
+	}
+
+	return def
+}
+
+func parseHex(s string) (color.RGBA, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
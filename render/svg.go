@@ -0,0 +1,123 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// Cell size of SVG in pixels, it fits monospace font of 14px.
+const (
+	svgCellWidth  = 8.4
+	svgCellHeight = 17
+	svgFontSize   = 14
+)
+
+// SVG draws screen as SVG image with text. Cursor is not drawn.
+func SVG(w io.Writer, screen *vt.Screen, palette Palette) error {
+	bw := bufio.NewWriter(w)
+
+	width, height := float64(screen.Width())*svgCellWidth, float64(screen.Height()*svgCellHeight)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="monospace" font-size="%d">`,
+		width, height, width, height, svgFontSize)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`, hex(palette.BG))
+
+	for y, line := range screen.Lines() {
+		svgBackground(bw, line, y, &palette)
+	}
+
+	fmt.Fprint(bw, `<g xml:space="preserve">`)
+
+	for y, line := range screen.Lines() {
+		svgText(bw, line, y, &palette)
+	}
+
+	fmt.Fprint(bw, "</g></svg>\n")
+
+	return bw.Flush()
+}
+
+// svgBackground draws runs of cells with non-default background.
+func svgBackground(w *bufio.Writer, line vt.Line, y int, palette *Palette) {
+	for x := 0; x < len(line.Cells); {
+		bg := palette.Color(line.Cells[x].Attr, false)
+
+		end := x + 1
+		for end < len(line.Cells) && palette.Color(line.Cells[end].Attr, false) == bg {
+			end++
+		}
+
+		if bg != palette.BG {
+			fmt.Fprintf(w, `<rect x="%g" y="%d" width="%g" height="%d" fill="%s"/>`,
+				float64(x)*svgCellWidth, y*svgCellHeight, float64(end-x)*svgCellWidth, svgCellHeight, hex(bg))
+		}
+
+		x = end
+	}
+}
+
+// svgText draws runs of characters with the same attributes, every run is positioned by its column.
+func svgText(w *bufio.Writer, line vt.Line, y int, palette *Palette) {
+	for x := 0; x < len(line.Cells); {
+		attr := line.Cells[x].Attr
+
+		end := x
+		text := make([]rune, 0, len(line.Cells)-x)
+
+		for end < len(line.Cells) && line.Cells[end].Attr == attr {
+			if r := line.Cells[end].Rune; line.Cells[end].Width > 0 {
+				text = append(text, r)
+			}
+
+			end++
+		}
+
+		// trailing spaces are not drawn
+		cells := end - x
+		for len(text) > 0 && text[len(text)-1] == ' ' {
+			text, cells = text[:len(text)-1], cells-1
+		}
+
+		if len(text) > 0 && attr.Flags&vt.Hidden == 0 {
+			// text length keeps columns aligned if font width differs from cell width
+			fmt.Fprintf(w, `<text x="%g" y="%g" textLength="%g" fill="%s"%s>`,
+				float64(x)*svgCellWidth, float64(y)*svgCellHeight+svgCellHeight*0.75, float64(cells)*svgCellWidth,
+				hex(palette.Color(attr, true)), svgStyle(attr))
+			xml.EscapeText(w, []byte(string(text)))
+			fmt.Fprint(w, "</text>")
+		}
+
+		x = end
+	}
+}
+
+func svgStyle(attr vt.Attr) string {
+	var style string
+
+	if attr.Flags&vt.Bold != 0 {
+		style += ` font-weight="bold"`
+	}
+
+	if attr.Flags&vt.Italic != 0 {
+		style += ` font-style="italic"`
+	}
+
+	if attr.Flags&vt.Faint != 0 {
+		style += ` opacity="0.6"`
+	}
+
+	switch {
+	case attr.Flags&vt.Underline != 0 && attr.Flags&vt.Strikethrough != 0:
+		style += ` text-decoration="underline line-through"`
+	case attr.Flags&vt.Underline != 0:
+		style += ` text-decoration="underline"`
+	case attr.Flags&vt.Strikethrough != 0:
+		style += ` text-decoration="line-through"`
+	}
+
+	return style
+}
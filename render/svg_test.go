@@ -0,0 +1,84 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+func TestPaletteFromTheme(t *testing.T) {
+	p, err := PaletteFromTheme(&player.Theme{
+		FG:      "#ffffff",
+		BG:      "#101010",
+		Palette: "#000001:#000002:#000003:#000004:#000005:#000006:#000007:#000008",
+	})
+	if err != nil {
+		t.Fatalf("PaletteFromTheme failed: %s", err)
+	}
+
+	if p.FG != (color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) || p.BG != (color.RGBA{R: 0x10, G: 0x10, B: 0x10, A: 0xff}) {
+		t.Fatalf("Unexpected default colors: %v %v", p.FG, p.BG)
+	}
+
+	// 8 colors are repeated for bright ones
+	if p.Colors[9] != (color.RGBA{B: 2, A: 0xff}) || p.Colors[196] != (color.RGBA{R: 0xff, A: 0xff}) {
+		t.Fatalf("Unexpected palette: %v %v", p.Colors[9], p.Colors[196])
+	}
+
+	if _, err = PaletteFromTheme(&player.Theme{FG: "white"}); err == nil {
+		t.Fatalf("Invalid color must be rejected")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	screen := vt.New(20, 3)
+	screen.Write([]byte("\x1b[1;31mred\x1b[0m \x1b[44mblue\x1b[0m\r\n<a & b>\r\n\x1b[8mhidden\x1b[0m"))
+
+	var buf bytes.Buffer
+	if err := SVG(&buf, screen, DefaultPalette()); err != nil {
+		t.Fatalf("SVG failed: %s", err)
+	}
+
+	var texts []string
+
+	dec := xml.NewDecoder(&buf)
+	for inText := false; ; {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("Invalid SVG: %s", err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			inText = token.Name.Local == "text"
+
+			for _, attr := range token.Attr {
+				if attr.Name.Local == "fill" {
+					texts = append(texts, attr.Value)
+				}
+			}
+		case xml.EndElement:
+			inText = false
+		case xml.CharData:
+			if inText {
+				texts = append(texts, string(token))
+			}
+		}
+	}
+
+	// background, blue background run, bold red text in bright color, text on blue and escaped text, hidden text is skipped
+	expected := "#000000|#0000ee|#ff0000|red|#cccccc|blue|#cccccc|<a & b>"
+	if strings.Join(texts, "|") != expected {
+		t.Fatalf("Unexpected SVG content: %q", texts)
+	}
+}
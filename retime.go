@@ -0,0 +1,197 @@
+package player
+
+import (
+	"math"
+)
+
+// typingPause is a maximal gap in seconds between input frames considered as continuous typing.
+const typingPause = 1.
+
+// ChangeSpeed returns FrameSource with scaled timeline. Values greater than 1 speed up playback,
+// values between 0 and 1 slow it down. Non-positive values are ignored.
+func ChangeSpeed(src FrameSource, speed float64) FrameSource {
+	if speed <= 0 {
+		return src
+	}
+
+	return TimeScale(src, 1/speed)
+}
+
+// LimitIdle returns FrameSource where gaps between frames are not longer than limit (like "idle_time_limit" of asciinema).
+// Non-positive values are ignored.
+func LimitIdle(src FrameSource, limit float64) FrameSource {
+	if limit <= 0 {
+		return src
+	}
+
+	return &deltaFrameSource{src: src, delta: func(delta float64) float64 { return math.Min(delta, limit) }}
+}
+
+// deltaFrameSource changes gaps between frames.
+type deltaFrameSource struct {
+	src   FrameSource
+	delta func(float64) float64
+
+	frame            Frame
+	srcTime, dstTime float64
+}
+
+func (s *deltaFrameSource) Header() Header { return s.src.Header() }
+
+func (s *deltaFrameSource) Next() bool {
+	if !s.src.Next() {
+		return false
+	}
+
+	s.frame = s.src.Frame()
+	s.dstTime += s.delta(math.Max(s.frame.Time-s.srcTime, 0))
+	s.srcTime = math.Max(s.frame.Time, s.srcTime)
+	s.frame.Time = s.dstTime
+
+	return true
+}
+
+func (s *deltaFrameSource) Frame() Frame { return s.frame }
+
+func (s *deltaFrameSource) Err() error { return s.src.Err() }
+
+// NormalizeTyping returns FrameSource with constant delay (in seconds) between typed characters for "perfect typing" demos.
+// Input frames are considered as typing if they are not more than a second apart. Frames between typed characters
+// (i.e. echo) are moved proportionally. Input must be recorded for this to work (see "asciinema rec --stdin").
+func NormalizeTyping(src FrameSource, delay float64) FrameSource {
+	if delay <= 0 {
+		return src
+	}
+
+	return &typingFrameSource{src: src, delay: delay}
+}
+
+// typingFrameSource buffers frames following input frame until next input frame to find out gap between them.
+type typingFrameSource struct {
+	src   FrameSource
+	delay float64
+
+	segment   []Frame // frames after last input frame
+	inputTime float64 // time of last input frame
+	hasInput  bool
+
+	queue            []Frame
+	frame            Frame
+	srcTime, dstTime float64
+	done             bool
+}
+
+func (s *typingFrameSource) Header() Header { return s.src.Header() }
+
+func (s *typingFrameSource) Next() bool {
+	for len(s.queue) == 0 {
+		if s.done {
+			return false
+		}
+
+		if !s.src.Next() {
+			s.done = true
+			s.flush(1)
+
+			continue
+		}
+
+		frame := s.src.Frame()
+		frame.Data = append([]byte(nil), frame.Data...)
+
+		switch {
+		case !s.hasInput:
+			// nothing typed yet
+			s.enqueue(frame, 1)
+		case frame.Time-s.inputTime > typingPause:
+			// pause after typing, keep as is
+			s.flush(1)
+			s.enqueue(frame, 1)
+		case frame.Type == InputFrame:
+			scale := 0.
+			if gap := frame.Time - s.inputTime; gap > 0 {
+				scale = s.delay / gap
+			}
+
+			s.flush(scale)
+			s.enqueue(frame, scale)
+		default:
+			s.segment = append(s.segment, frame)
+			continue
+		}
+
+		s.hasInput = frame.Type == InputFrame
+		if s.hasInput {
+			s.inputTime = frame.Time
+		}
+	}
+
+	s.frame, s.queue = s.queue[0], s.queue[1:]
+
+	return true
+}
+
+// flush queues buffered segment with gaps scaled.
+func (s *typingFrameSource) flush(scale float64) {
+	for _, frame := range s.segment {
+		s.enqueue(frame, scale)
+	}
+
+	s.segment = s.segment[:0]
+	s.hasInput = false
+}
+
+func (s *typingFrameSource) enqueue(frame Frame, scale float64) {
+	s.dstTime += math.Max(frame.Time-s.srcTime, 0) * scale
+	s.srcTime = math.Max(frame.Time, s.srcTime)
+	frame.Time = s.dstTime
+	s.queue = append(s.queue, frame)
+}
+
+func (s *typingFrameSource) Frame() Frame { return s.frame }
+
+func (s *typingFrameSource) Err() error { return s.src.Err() }
+
+// HoldEnd returns FrameSource with empty output frame added after hold seconds since last frame,
+// so last screen stays visible for a while. Non-positive values are ignored.
+func HoldEnd(src FrameSource, hold float64) FrameSource {
+	if hold <= 0 {
+		return src
+	}
+
+	return &holdFrameSource{src: src, hold: hold}
+}
+
+type holdFrameSource struct {
+	src  FrameSource
+	hold float64
+
+	frame Frame
+	done  bool
+}
+
+func (s *holdFrameSource) Header() Header { return s.src.Header() }
+
+func (s *holdFrameSource) Next() bool {
+	if s.done {
+		return false
+	}
+
+	if s.src.Next() {
+		s.frame = s.src.Frame()
+		return true
+	}
+
+	s.done = true
+	if s.src.Err() != nil {
+		return false
+	}
+
+	s.frame = Frame{Time: s.frame.Time + s.hold, Type: OutputFrame, Data: []byte{}}
+
+	return true
+}
+
+func (s *holdFrameSource) Frame() Frame { return s.frame }
+
+func (s *holdFrameSource) Err() error { return s.src.Err() }
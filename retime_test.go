@@ -0,0 +1,108 @@
+package player_test
+
+import (
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestChangeSpeed(t *testing.T) {
+	src := player.ChangeSpeed(mustSourceFromString(t, editTestCast), 2)
+
+	expected := []float64{0.5, 1, 1, 1.5, 1.5, 2, 2.5}
+
+	if actual := frameTimes(readFrames(t, src)); !timesEqual(expected, actual) {
+		t.Fatalf("Unexpected times: %v", actual)
+	}
+}
+
+func TestLimitIdle(t *testing.T) {
+	src := player.LimitIdle(mustSourceFromString(t, `{"version":2,"width":80,"height":24}
+[0.5,"o","a"]
+[5,"o","b"]
+[5.2,"o","c"]
+[10,"o","d"]
+`), 1)
+
+	expected := []float64{0.5, 1.5, 1.7, 2.7}
+
+	if actual := frameTimes(readFrames(t, src)); !timesEqual(expected, actual) {
+		t.Fatalf("Unexpected times: %v", actual)
+	}
+}
+
+func TestNormalizeTyping(t *testing.T) {
+	src := player.NormalizeTyping(mustSourceFromString(t, `{"version":2,"width":80,"height":24}
+[1,"o","$ "]
+[2,"i","l"]
+[2.1,"o","l"]
+[2.2,"i","s"]
+[2.25,"o","s"]
+[2.6,"i","\r"]
+[2.7,"o","\r\n"]
+[5,"o","file"]
+`), 0.1)
+
+	expected := []player.Frame{
+		{Time: 1, Type: player.OutputFrame, Data: []byte("$ ")},
+		{Time: 2, Type: player.InputFrame, Data: []byte("l")},
+		{Time: 2.05, Type: player.OutputFrame, Data: []byte("l")},
+		{Time: 2.1, Type: player.InputFrame, Data: []byte("s")},
+		{Time: 2.1125, Type: player.OutputFrame, Data: []byte("s")},
+		{Time: 2.2, Type: player.InputFrame, Data: []byte("\r")},
+		{Time: 2.3, Type: player.OutputFrame, Data: []byte("\r\n")},
+		{Time: 4.6, Type: player.OutputFrame, Data: []byte("file")},
+	}
+
+	actual := readFrames(t, src)
+	if len(actual) != len(expected) {
+		t.Fatalf("Unexpected frames: %+v", actual)
+	}
+
+	for i := range expected {
+		if !almostEqual(expected[i].Time, actual[i].Time) || expected[i].Type != actual[i].Type ||
+			string(expected[i].Data) != string(actual[i].Data) {
+			t.Fatalf("Unexpected frame %d: %+v", i, actual[i])
+		}
+	}
+}
+
+func TestHoldEnd(t *testing.T) {
+	src := player.HoldEnd(mustSourceFromString(t, editTestCast), 3)
+
+	frames := readFrames(t, src)
+	last := frames[len(frames)-1]
+
+	if len(frames) != 8 || last.Time != 8 || last.Type != player.OutputFrame || len(last.Data) != 0 {
+		t.Fatalf("Unexpected frames: %+v", frames)
+	}
+}
+
+func frameTimes(frames []player.Frame) []float64 {
+	times := make([]float64, 0, len(frames))
+	for _, frame := range frames {
+		times = append(times, frame.Time)
+	}
+
+	return times
+}
+
+func timesEqual(expected, actual []float64) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	for i := range expected {
+		if !almostEqual(expected[i], actual[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func almostEqual(a, b float64) bool {
+	const epsilon = 1e-9
+
+	return a-b < epsilon && b-a < epsilon
+}
@@ -0,0 +1,88 @@
+package player
+
+import (
+	"regexp"
+
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// searchSettleTime is a minimal pause in seconds after output before screen is checked.
+// Applications often redraw screen with several writes, checking screen in the middle of redraw gives duplicate hits.
+const searchSettleTime = 0.01
+
+// SearchHit is an appearance of text matching pattern on screen.
+type SearchHit struct {
+	// Time of output that made text visible.
+	Time float64
+
+	// Match is a matched text.
+	Match string
+
+	// Line is a screen line containing match. Lines wrapped because of screen width are joined.
+	Line string
+}
+
+// Search plays recording into virtual terminal and returns moments when text matching pattern appears on screen.
+// Matching is done against visible text so escape sequences, overwritten and erased characters don't affect it.
+// Text is reported again only if it disappeared from screen and then appeared again or appeared at another column,
+// so text typed after match or scrolling of screen doesn't repeat hits.
+func Search(src FrameSource, pattern *regexp.Regexp) ([]SearchHit, error) {
+	hdr := src.Header()
+
+	type occurrence struct {
+		match  string
+		column int // in line joined from wrapped ones
+	}
+
+	var (
+		screen   = vt.New(hdr.Width, hdr.Height, vt.WithScrollback(0))
+		visible  = map[occurrence]int{} // number of matches currently on screen
+		hits     []SearchHit
+		lastTime float64
+		dirty    bool
+	)
+
+	check := func() {
+		current := map[occurrence]int{}
+
+		for _, line := range vt.JoinWrapped(screen.Lines()) {
+			for _, loc := range pattern.FindAllStringIndex(line, -1) {
+				occ := occurrence{match: line[loc[0]:loc[1]], column: loc[0]}
+				if current[occ]++; current[occ] > visible[occ] {
+					hits = append(hits, SearchHit{Time: lastTime, Match: occ.match, Line: line})
+				}
+			}
+		}
+
+		visible, dirty = current, false
+	}
+
+	for src.Next() {
+		frame := src.Frame()
+
+		if dirty && frame.Time-lastTime >= searchSettleTime {
+			check()
+		}
+
+		switch frame.Type {
+		case OutputFrame:
+			screen.Write(frame.Data)
+			lastTime, dirty = frame.Time, true
+		case ResizeFrame:
+			if width, height, err := ParseResizeData(frame.Data); err == nil {
+				screen.Resize(width, height)
+				lastTime, dirty = frame.Time, true
+			}
+		}
+	}
+
+	if err := src.Err(); err != nil {
+		return nil, err
+	}
+
+	if dirty {
+		check()
+	}
+
+	return hits, nil
+}
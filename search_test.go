@@ -0,0 +1,59 @@
+package player_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestSearch(t *testing.T) {
+	src := mustSourceFromString(t, `{"version":2,"width":20,"height":3}
+[1,"o","$ kub"]
+[1.5,"o","\u001b[1mectl\u001b[0m del"]
+[2,"o","ete pod\r\n"]
+[3,"o","pod deleted\r\n$ "]
+[4,"o","echo kubectl delete\r\n"]
+[5,"o","\u001b[2J\u001b[H"]
+[5.001,"o","kubectl delete"]
+[6,"o","\r\n\r\n\r\n\r\n"]
+[7,"o","\u001b[Hkubectl\u001b[10Gdelete"]
+`)
+
+	hits, err := player.Search(src, regexp.MustCompile(`kubectl\s+delete`))
+	if err != nil {
+		t.Fatalf("Search failed: %s", err)
+	}
+
+	expected := []player.SearchHit{
+		{Time: 2, Match: "kubectl delete", Line: "$ kubectl delete pod"},
+		{Time: 4, Match: "kubectl delete", Line: "$ echo kubectl delete"},
+		{Time: 5.001, Match: "kubectl delete", Line: "kubectl delete"},
+		{Time: 7, Match: "kubectl  delete", Line: "kubectl  delete"},
+	}
+
+	if !reflect.DeepEqual(expected, hits) {
+		t.Fatalf("Unexpected hits: %+v", hits)
+	}
+}
+
+func TestSearch_Typing(t *testing.T) {
+	cast := `{"version":2,"width":40,"height":3}` + "\n"
+	for i, c := range "$ kubectl delete pod foo --now" {
+		cast += fmt.Sprintf("[%g,\"o\",%q]\n", float64(i)/10, string(c))
+	}
+
+	cast += "[5,\"o\",\"\\r\\npod \\\"foo\\\" deleted\\r\\n$ \"]\n"
+
+	hits, err := player.Search(mustSourceFromString(t, cast), regexp.MustCompile(`kubectl delete pod foo`))
+	if err != nil {
+		t.Fatalf("Search failed: %s", err)
+	}
+
+	// keystrokes after match and scrolling don't repeat it
+	if len(hits) != 1 || hits[0].Time != 2.3 {
+		t.Fatalf("Unexpected hits: %+v", hits)
+	}
+}
@@ -0,0 +1,106 @@
+package sshterm
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/internal/remote"
+)
+
+// Server plays recordings from file system to SSH clients. Client chooses recording from menu,
+// menu is shown again after playback.
+type Server struct {
+	// Config of SSH connections, it must contain host key.
+	Config *ssh.ServerConfig
+
+	// FS contains recordings, all "*.cast" files are listed in menu.
+	FS fs.FS
+
+	// Options for Player.
+	Options []player.Option
+
+	// ErrorLog logs failed connections and sessions. Standard logger is used if nil.
+	ErrorLog *log.Logger
+}
+
+// Serve accepts connections on listener and serves them. It returns error returned by listener.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves SSH connection. Every session of connection gets its own menu.
+func (s *Server) ServeConn(conn net.Conn) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.Config)
+	if err != nil {
+		conn.Close()
+		s.logf("ssh handshake with %s failed: %s", conn.RemoteAddr(), err)
+
+		return
+	}
+
+	defer sconn.Close()
+
+	go ssh.DiscardRequests(reqs)
+
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			newCh.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			s.logf("channel accept failed: %s", err)
+			continue
+		}
+
+		go s.serveSession(ch, chReqs)
+	}
+}
+
+func (s *Server) serveSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	term, err := New(ch, reqs)
+	if err != nil {
+		fmt.Fprintf(ch.Stderr(), "%s\r\n", err)
+		sendExitStatus(ch, 1)
+		ch.Close()
+
+		return
+	}
+
+	defer term.Close()
+
+	status := 0
+	menu := &remote.Menu{FS: s.FS, Options: s.Options}
+
+	if err = menu.Run(term, term.console); err != nil {
+		s.logf("session failed: %s", err)
+		status = 1
+	}
+
+	sendExitStatus(ch, status)
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+func sendExitStatus(ch ssh.Channel, status int) {
+	ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+}
@@ -0,0 +1,182 @@
+package sshterm
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const testCast = `{"version":2,"width":20,"height":5}
+[0.01,"o","hello "]
+[0.02,"o","world"]
+[60,"o","!"]
+`
+
+// output collects session output.
+type output struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+// waitFor waits until output after offset contains text and returns offset after it.
+func (o *output) waitFor(t *testing.T, offset int, text string) int {
+	t.Helper()
+
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		o.mu.Lock()
+		out := o.buf.String()
+		o.mu.Unlock()
+
+		if i := strings.Index(out[offset:], text); i >= 0 {
+			return offset + i + len(text)
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("%q not found in output %q", text, out[offset:])
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestServer(t *testing.T) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Key generate failed: %s", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("Signer create failed: %s", err)
+	}
+
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+
+	srv := &Server{
+		Config: config,
+		FS: fstest.MapFS{
+			"demo/test.cast": {Data: []byte(testCast)},
+			"big.cast":       {Data: []byte(`{"version":2,"width":200,"height":5}` + "\n")},
+			"notes.txt":      {Data: []byte("not a cast")},
+		},
+		Options: []player.Option{player.WithMaxWait(time.Second)},
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %s", err)
+	}
+
+	t.Cleanup(func() { lis.Close() })
+
+	go srv.Serve(lis)
+
+	return lis.Addr().String()
+}
+
+func newSession(t *testing.T, addr string, pty bool) (*ssh.Session, io.Writer, *output) {
+	t.Helper()
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            "demo",
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("Session create failed: %s", err)
+	}
+
+	if pty {
+		if err = session.RequestPty("xterm", 24, 80, ssh.TerminalModes{}); err != nil {
+			t.Fatalf("Pty request failed: %s", err)
+		}
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		t.Fatalf("Stdin pipe failed: %s", err)
+	}
+
+	out := &output{}
+	session.Stdout = out
+	session.Stderr = out
+
+	return session, stdin, out
+}
+
+func TestServer(t *testing.T) {
+	session, stdin, out := newSession(t, newTestServer(t), true)
+
+	if err := session.Shell(); err != nil {
+		t.Fatalf("Shell failed: %s", err)
+	}
+
+	offset := out.waitFor(t, 0, "   1) big.cast\r\n   2) demo/test.cast\r\n")
+
+	io.WriteString(stdin, "1\r")
+	offset = out.waitFor(t, offset, player.ErrSmallTerminal.Error()+", 200x5 required")
+
+	// long pause is skipped by seek
+	io.WriteString(stdin, "2\r")
+	offset = out.waitFor(t, offset, "hello world")
+	io.WriteString(stdin, "\033[C")
+	offset = out.waitFor(t, offset, "!")
+	offset = out.waitFor(t, offset, "press any key")
+
+	// playback is stopped by "q"
+	io.WriteString(stdin, " 2\r")
+	offset = out.waitFor(t, offset, "hello world")
+	io.WriteString(stdin, "q")
+	out.waitFor(t, offset, "press any key")
+
+	io.WriteString(stdin, " q\r")
+
+	if err := session.Wait(); err != nil {
+		t.Fatalf("Unexpected exit: %s", err)
+	}
+}
+
+func TestServer_NoPTY(t *testing.T) {
+	session, _, out := newSession(t, newTestServer(t), false)
+
+	if err := session.Shell(); err == nil {
+		t.Fatalf("Shell without pty must fail")
+	}
+
+	session.Close()
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+
+	if strings.Contains(out.buf.String(), "Recordings") {
+		t.Fatalf("Menu shown without pty")
+	}
+}
@@ -0,0 +1,170 @@
+// Package sshterm provides player.Terminal working over SSH session and SSH server playing recordings to its clients.
+//
+// Client must request pseudo-terminal, its size is used as terminal dimensions. Keystrokes control playback:
+// "Space" pauses and continues, "q" or "Ctrl+C" stops, "Left" and "Right" arrows seek backward and forward.
+package sshterm
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/internal/remote"
+)
+
+var (
+	ErrNoPTY   = fmt.Errorf("pseudo-terminal required")
+	ErrNoShell = fmt.Errorf("session closed before shell request")
+)
+
+// ptyRequest is a payload of "pty-req" request (RFC 4254, section 6.2).
+type ptyRequest struct {
+	Term          string
+	Columns, Rows uint32
+	Width, Height uint32
+	Modes         string
+}
+
+// windowChange is a payload of "window-change" request (RFC 4254, section 6.7).
+type windowChange struct {
+	Columns, Rows uint32
+	Width, Height uint32
+}
+
+// Term is a player.Terminal working over SSH session channel.
+type Term struct {
+	ch      ssh.Channel
+	input   chan byte // closed when client closes input
+	console *remote.Console
+
+	mu            sync.Mutex
+	width, height int
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New handles session requests until shell or exec request: pseudo-terminal must be requested before it.
+// Command of exec request is ignored. Window size changes are tracked after that.
+func New(ch ssh.Channel, reqs <-chan *ssh.Request) (*Term, error) {
+	t := &Term{
+		ch:     ch,
+		input:  make(chan byte, 64),
+		closed: make(chan struct{}),
+	}
+
+	t.console = &remote.Console{Output: ch, Input: t.input, Closed: t.closed}
+
+	pty := false
+
+	for req := range reqs {
+		switch req.Type {
+		case "pty-req":
+			var payload ptyRequest
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				req.Reply(false, nil)
+				return nil, fmt.Errorf("malformed pty request: %w", err)
+			}
+
+			t.width, t.height, pty = int(payload.Columns), int(payload.Rows), true
+
+			req.Reply(true, nil)
+		case "shell", "exec":
+			if !pty {
+				req.Reply(false, nil)
+				return nil, ErrNoPTY
+			}
+
+			req.Reply(true, nil)
+
+			go t.handleRequests(reqs)
+			go t.readInput()
+
+			return t, nil
+		default:
+			if req.WantReply {
+				req.Reply(false, nil)
+			}
+		}
+	}
+
+	return nil, ErrNoShell
+}
+
+func (t *Term) handleRequests(reqs <-chan *ssh.Request) {
+	for req := range reqs {
+		ok := false
+
+		if req.Type == "window-change" {
+			var payload windowChange
+			if ssh.Unmarshal(req.Payload, &payload) == nil {
+				t.mu.Lock()
+				t.width, t.height = int(payload.Columns), int(payload.Rows)
+				t.mu.Unlock()
+
+				ok = true
+			}
+		}
+
+		if req.WantReply {
+			req.Reply(ok, nil)
+		}
+	}
+}
+
+func (t *Term) readInput() {
+	defer close(t.input)
+
+	var buf [256]byte
+
+	for {
+		n, err := t.ch.Read(buf[:])
+		for _, b := range buf[:n] {
+			select {
+			case t.input <- b:
+			case <-t.closed:
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Write sends output to client.
+func (t *Term) Write(p []byte) (n int, err error) { return t.ch.Write(p) }
+
+// Close closes session channel.
+func (t *Term) Close() error {
+	var err error
+
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		err = t.ch.Close()
+	})
+
+	return err
+}
+
+// Dimensions returns size of client terminal.
+func (t *Term) Dimensions() (width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.width, t.height
+}
+
+// ToRaw does nothing: client terminal is put to raw mode by client.
+func (t *Term) ToRaw() error { return nil }
+
+// Restore does nothing.
+func (t *Term) Restore() error { return nil }
+
+// Control maps keystrokes of client to playback control until Close. Playback is stopped if client closes input.
+func (t *Term) Control(control player.PlaybackControl) {
+	t.console.Control(control, t.closed)
+}
@@ -0,0 +1,218 @@
+package player
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/xakep666/asciinema-player/v3/internal/ansi"
+)
+
+// statsPauses is a number of longest pauses kept in Stats.
+const statsPauses = 5
+
+// Names of terminal features reported in Stats.
+const (
+	FeatureAlternateScreen = "alternate-screen"
+	FeatureMouse           = "mouse"
+	FeatureBracketedPaste  = "bracketed-paste"
+	FeatureHiddenCursor    = "hidden-cursor"
+	FeatureScrollRegion    = "scroll-region"
+	FeatureTextAttributes  = "text-attributes"
+	FeatureColors16        = "colors-16"
+	FeatureColors256       = "colors-256"
+	FeatureTrueColor       = "truecolor"
+	FeatureTitle           = "title"
+	FeatureHyperlinks      = "hyperlinks"
+	FeatureClipboard       = "clipboard"
+	FeatureWindowResize    = "window-resize"
+)
+
+// Stats is a summary of recording.
+type Stats struct {
+	Header Header
+
+	// Duration is a time of last frame in seconds.
+	Duration float64
+
+	// CompressedDuration is a duration with pauses limited by idle limit passed to CollectStats.
+	CompressedDuration float64
+
+	// Frames contains number of frames per type.
+	Frames map[FrameType]int
+
+	// Bytes contains size of frames data per type.
+	Bytes map[FrameType]int
+
+	// Pauses contains longest gaps between frames, longest first.
+	Pauses []Pause
+
+	Markers []Marker
+
+	Resizes []Resize
+
+	// Features contains sorted names of terminal features used by output (see Feature* constants).
+	Features []string
+}
+
+// Pause is a gap between frames.
+type Pause struct {
+	// Time when pause begins.
+	Time float64
+
+	Duration float64
+}
+
+// Marker is a MarkerFrame.
+type Marker struct {
+	Time  float64
+	Label string
+}
+
+// Resize is a ResizeFrame.
+type Resize struct {
+	Time          float64
+	Width, Height int
+}
+
+// CollectStats reads all frames from source and returns summary. Pauses longer than idleLimit (in seconds)
+// are limited to it in CompressedDuration, non-positive idleLimit means no limit.
+func CollectStats(src FrameSource, idleLimit float64) (*Stats, error) {
+	stats := &Stats{
+		Header: src.Header(),
+		Frames: map[FrameType]int{},
+		Bytes:  map[FrameType]int{},
+	}
+
+	var (
+		parser   ansi.Parser
+		features = map[string]bool{}
+		lastTime float64
+	)
+
+	for src.Next() {
+		frame := src.Frame()
+
+		if pause := frame.Time - lastTime; pause > 0 {
+			stats.Duration += pause
+			stats.CompressedDuration += pause
+			if idleLimit > 0 && pause > idleLimit {
+				stats.CompressedDuration -= pause - idleLimit
+			}
+
+			stats.addPause(Pause{Time: lastTime, Duration: pause})
+			lastTime = frame.Time
+		}
+
+		stats.Frames[frame.Type]++
+		stats.Bytes[frame.Type] += len(frame.Data)
+
+		switch frame.Type {
+		case OutputFrame:
+			parser.Feed(frame.Data, func(seq *ansi.Sequence) { detectFeatures(seq, features) })
+		case MarkerFrame:
+			stats.Markers = append(stats.Markers, Marker{Time: frame.Time, Label: string(frame.Data)})
+		case ResizeFrame:
+			if width, height, err := ParseResizeData(frame.Data); err == nil {
+				stats.Resizes = append(stats.Resizes, Resize{Time: frame.Time, Width: width, Height: height})
+			}
+		}
+	}
+
+	if err := src.Err(); err != nil {
+		return nil, err
+	}
+
+	stats.Features = make([]string, 0, len(features))
+	for feature := range features {
+		stats.Features = append(stats.Features, feature)
+	}
+
+	sort.Strings(stats.Features)
+
+	return stats, nil
+}
+
+// addPause keeps statsPauses longest pauses.
+func (s *Stats) addPause(pause Pause) {
+	i := sort.Search(len(s.Pauses), func(i int) bool { return s.Pauses[i].Duration < pause.Duration })
+	if i >= statsPauses {
+		return
+	}
+
+	s.Pauses = append(s.Pauses, Pause{})
+	copy(s.Pauses[i+1:], s.Pauses[i:])
+	s.Pauses[i] = pause
+
+	if len(s.Pauses) > statsPauses {
+		s.Pauses = s.Pauses[:statsPauses]
+	}
+}
+
+func detectFeatures(seq *ansi.Sequence, features map[string]bool) {
+	switch seq.Kind {
+	case ansi.CSI:
+		switch {
+		case seq.Prefix == '?' && seq.Final == 'h':
+			for _, param := range seq.Params {
+				switch param {
+				case 47, 1047, 1049:
+					features[FeatureAlternateScreen] = true
+				case 1000, 1002, 1003, 1005, 1006, 1015:
+					features[FeatureMouse] = true
+				case 2004:
+					features[FeatureBracketedPaste] = true
+				}
+			}
+		case seq.Prefix == '?' && seq.Final == 'l':
+			for _, param := range seq.Params {
+				if param == 25 {
+					features[FeatureHiddenCursor] = true
+				}
+			}
+		case seq.Prefix == 0 && seq.Final == 'r':
+			features[FeatureScrollRegion] = true
+		case seq.Prefix == 0 && seq.Final == 't' && seq.Param(0, 0) == 8:
+			features[FeatureWindowResize] = true
+		case seq.Prefix == 0 && seq.Final == 'm':
+			detectSGRFeatures(seq.Params, features)
+		}
+	case ansi.OSC:
+		command := seq.Data
+		if i := bytes.IndexByte(command, ';'); i >= 0 {
+			command = command[:i]
+		}
+
+		switch string(command) {
+		case "0", "1", "2":
+			features[FeatureTitle] = true
+		case "8":
+			features[FeatureHyperlinks] = true
+		case "52":
+			features[FeatureClipboard] = true
+		}
+	}
+}
+
+func detectSGRFeatures(params []int, features map[string]bool) {
+	for i := 0; i < len(params); i++ {
+		switch param := params[i]; {
+		case param == 38 || param == 48 || param == 58:
+			if i+1 >= len(params) {
+				return
+			}
+
+			switch params[i+1] {
+			case 5:
+				features[FeatureColors256] = true
+				i += 2
+			case 2:
+				features[FeatureTrueColor] = true
+				i += 4
+			}
+		case param >= 30 && param <= 37, param >= 40 && param <= 47, param >= 90 && param <= 97, param >= 100 && param <= 107:
+			features[FeatureColors16] = true
+		case param >= 1 && param <= 9:
+			features[FeatureTextAttributes] = true
+		}
+	}
+}
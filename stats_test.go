@@ -0,0 +1,53 @@
+package player_test
+
+import (
+	"reflect"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestCollectStats(t *testing.T) {
+	src := mustSourceFromString(t, `{"version":2,"width":80,"height":24,"timestamp":1000}
+[0.5,"o","\u001b[?1049h\u001b[1;31mred\u001b[0m"]
+[1,"i","q"]
+[6,"o","\u001b[38;5;100mx\u001b]0;title\u0007"]
+[6.5,"m","chapter"]
+[7,"r","100x30"]
+[10,"o","\u001b[?1000h\u001b[?1049l"]
+`)
+
+	stats, err := player.CollectStats(src, 2)
+	if err != nil {
+		t.Fatalf("CollectStats failed: %s", err)
+	}
+
+	expected := &player.Stats{
+		Header:             player.Header{Version: 2, Width: 80, Height: 24, Timestamp: 1000},
+		Duration:           10,
+		CompressedDuration: 6,
+		Frames:             map[player.FrameType]int{player.OutputFrame: 3, player.InputFrame: 1, player.MarkerFrame: 1, player.ResizeFrame: 1},
+		Bytes:              map[player.FrameType]int{player.OutputFrame: 60, player.InputFrame: 1, player.MarkerFrame: 7, player.ResizeFrame: 6},
+		Pauses: []player.Pause{
+			{Time: 1, Duration: 5},
+			{Time: 7, Duration: 3},
+			{Time: 0, Duration: 0.5},
+			{Time: 0.5, Duration: 0.5},
+			{Time: 6, Duration: 0.5},
+		},
+		Markers: []player.Marker{{Time: 6.5, Label: "chapter"}},
+		Resizes: []player.Resize{{Time: 7, Width: 100, Height: 30}},
+		Features: []string{
+			player.FeatureAlternateScreen,
+			player.FeatureColors16,
+			player.FeatureColors256,
+			player.FeatureMouse,
+			player.FeatureTextAttributes,
+			player.FeatureTitle,
+		},
+	}
+
+	if !reflect.DeepEqual(expected, stats) {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,213 @@
+package player
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrTerminalStopped is reported by TeeTerminal.Errors for secondary terminal that called Stop, i.e. disconnected client.
+var ErrTerminalStopped = fmt.Errorf("terminal stopped playback")
+
+// TeeTerminal plays to several terminals at once: i.e. to local terminal, log file and websocket client.
+//
+// Primary terminal is handled like single terminal: its errors are returned to Player. Errors of secondary
+// terminals are isolated: failed terminal is excluded from playback, its error is available from Errors.
+// Dimensions are minimal of all terminals, control inputs of all terminals control the same playback
+// except Stop: it only excludes secondary terminal from playback.
+type TeeTerminal struct {
+	primary   Terminal
+	secondary []Terminal
+
+	mu   sync.Mutex
+	errs []error // errors of secondary terminals, nil for working ones
+	raw  []bool  // secondary terminals successfully put to raw mode
+}
+
+// NewTeeTerminal constructs TeeTerminal.
+func NewTeeTerminal(primary Terminal, secondary ...Terminal) *TeeTerminal {
+	return &TeeTerminal{
+		primary:   primary,
+		secondary: secondary,
+		errs:      make([]error, len(secondary)),
+		raw:       make([]bool, len(secondary)),
+	}
+}
+
+// Write writes output to all working terminals. Only error of primary terminal is returned.
+func (t *TeeTerminal) Write(p []byte) (n int, err error) {
+	if n, err = t.primary.Write(p); err != nil {
+		return n, err
+	}
+
+	t.each(func(i int, term Terminal) error {
+		_, err := term.Write(p)
+		return err
+	})
+
+	return n, nil
+}
+
+// Close closes all terminals. Only error of primary terminal is returned.
+func (t *TeeTerminal) Close() error {
+	err := t.primary.Close()
+
+	// failed terminals are closed too to stop their control loops
+	for i, term := range t.secondary {
+		if closeErr := term.Close(); closeErr != nil {
+			t.setErr(i, fmt.Errorf("close failed: %w", closeErr))
+		}
+	}
+
+	return err
+}
+
+// Dimensions returns minimal width and height of all terminals.
+func (t *TeeTerminal) Dimensions() (width, height int) {
+	width, height = t.primary.Dimensions()
+
+	for _, term := range t.secondary {
+		w, h := term.Dimensions()
+
+		if w < width {
+			width = w
+		}
+
+		if h < height {
+			height = h
+		}
+	}
+
+	return width, height
+}
+
+// ToRaw puts all terminals to raw mode. Only error of primary terminal is returned.
+func (t *TeeTerminal) ToRaw() error {
+	if err := t.primary.ToRaw(); err != nil {
+		return err
+	}
+
+	t.each(func(i int, term Terminal) error {
+		if err := term.ToRaw(); err != nil {
+			return fmt.Errorf("put to raw mode failed: %w", err)
+		}
+
+		t.mu.Lock()
+		t.raw[i] = true
+		t.mu.Unlock()
+
+		return nil
+	})
+
+	return nil
+}
+
+// Restore restores all terminals put to raw mode, including ones failed after that. Only error of primary terminal is returned.
+func (t *TeeTerminal) Restore() error {
+	for i, term := range t.secondary {
+		t.mu.Lock()
+		raw := t.raw[i]
+		t.raw[i] = false
+		t.mu.Unlock()
+
+		if !raw {
+			continue
+		}
+
+		if err := term.Restore(); err != nil {
+			t.setErr(i, fmt.Errorf("restore failed: %w", err))
+		}
+	}
+
+	return t.primary.Restore()
+}
+
+// Control runs control loops of all terminals. Inputs of failed secondary terminals are ignored,
+// secondary terminal calling Stop fails with ErrTerminalStopped.
+func (t *TeeTerminal) Control(control PlaybackControl) {
+	var wg sync.WaitGroup
+
+	wg.Add(1 + len(t.secondary))
+
+	go func() {
+		defer wg.Done()
+		t.primary.Control(control)
+	}()
+
+	for i, term := range t.secondary {
+		go func(i int, term Terminal) {
+			defer wg.Done()
+			term.Control(&teeControl{PlaybackControl: control, tee: t, index: i})
+		}(i, term)
+	}
+
+	wg.Wait()
+}
+
+// Errors returns errors of secondary terminals in order of NewTeeTerminal arguments, nil for working ones.
+func (t *TeeTerminal) Errors() []error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]error(nil), t.errs...)
+}
+
+// each calls fn for working secondary terminals, terminal fails if fn returns error.
+func (t *TeeTerminal) each(fn func(i int, term Terminal) error) {
+	for i, term := range t.secondary {
+		if t.failed(i) {
+			continue
+		}
+
+		if err := fn(i, term); err != nil {
+			t.setErr(i, err)
+		}
+	}
+}
+
+func (t *TeeTerminal) failed(i int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.errs[i] != nil
+}
+
+// setErr fails terminal, first error is kept.
+func (t *TeeTerminal) setErr(i int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.errs[i] == nil {
+		t.errs[i] = err
+	}
+}
+
+// teeControl ignores inputs of failed secondary terminal. Stop fails terminal instead of stopping playback
+// because it's called by terminal going away, i.e. disconnected websocket client.
+type teeControl struct {
+	PlaybackControl
+
+	tee   *TeeTerminal
+	index int
+}
+
+func (c *teeControl) Pause() {
+	if !c.tee.failed(c.index) {
+		c.PlaybackControl.Pause()
+	}
+}
+
+func (c *teeControl) Stop() {
+	c.tee.setErr(c.index, ErrTerminalStopped)
+}
+
+func (c *teeControl) Seek(seconds float64) {
+	if !c.tee.failed(c.index) {
+		c.PlaybackControl.Seek(seconds)
+	}
+}
+
+func (c *teeControl) SetSpeed(speed float64) {
+	if !c.tee.failed(c.index) {
+		c.PlaybackControl.SetSpeed(speed)
+	}
+}
@@ -0,0 +1,123 @@
+package player_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+type failingTerminal struct {
+	bufferTerminal
+	writes int
+}
+
+func (f *failingTerminal) Write(p []byte) (int, error) {
+	if f.writes++; f.writes > 1 {
+		return 0, errors.New("broken pipe")
+	}
+
+	return f.bufferTerminal.Write(p)
+}
+
+func TestTeeTerminal(t *testing.T) {
+	source, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":80,"height":24}
+[0.1,"o","a"]
+[0.2,"o","b"]
+[60,"o","c"]
+`))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	primary := &bufferTerminal{Width: 100, Height: 24}
+	failing := &failingTerminal{bufferTerminal: bufferTerminal{Width: 120, Height: 50}}
+	secondary := &bufferTerminal{Width: 80, Height: 40}
+	control := &controlTerminal{writes: make(chan string, 100), control: make(chan player.PlaybackControl, 1)}
+
+	tee := player.NewTeeTerminal(primary, failing, secondary, control)
+
+	if w, h := tee.Dimensions(); w != 80 || h != 24 {
+		t.Fatalf("Unexpected dimensions %dx%d", w, h)
+	}
+
+	p, err := player.NewPlayer(source, tee)
+	if err != nil {
+		t.Fatalf("Player setup failed: %s", err)
+	}
+
+	errCh := make(chan error, 1)
+	start := time.Now()
+
+	go func() { errCh <- p.Start() }()
+
+	// input of secondary terminal controls playback
+	<-control.writes
+	<-control.writes
+	(<-control.control).Seek(60)
+
+	if err = <-errCh; err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Playback took too long: %s", elapsed)
+	}
+
+	if primary.String() != "abc" || secondary.String() != "abc" || failing.String() != "a" {
+		t.Fatalf("Unexpected output: %q, %q, %q", primary.String(), secondary.String(), failing.String())
+	}
+
+	errs := tee.Errors()
+	if len(errs) != 3 || errs[0] == nil || errs[1] != nil || errs[2] != nil {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+}
+
+func TestTeeTerminal_SecondaryStop(t *testing.T) {
+	source, err := player.NewStreamFrameSource(strings.NewReader(`{"version":2,"width":80,"height":24}
+[0.1,"o","a"]
+[0.2,"o","b"]
+[0.3,"o","c"]
+`))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	primary := &bufferTerminal{Width: 80, Height: 24}
+	control := &controlTerminal{writes: make(chan string, 100), control: make(chan player.PlaybackControl, 1)}
+
+	tee := player.NewTeeTerminal(primary, control)
+
+	p, err := player.NewPlayer(source, tee)
+	if err != nil {
+		t.Fatalf("Player setup failed: %s", err)
+	}
+
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- p.Start() }()
+
+	// secondary terminal disconnects during playback
+	<-control.writes
+	(<-control.control).Stop()
+
+	if err = <-errCh; err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+
+	if primary.String() != "abc" {
+		t.Fatalf("Unexpected output: %q", primary.String())
+	}
+
+	if len(control.writes) != 0 {
+		t.Fatalf("Stopped terminal got writes")
+	}
+
+	errs := tee.Errors()
+	if len(errs) != 1 || !errors.Is(errs[0], player.ErrTerminalStopped) {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+}
@@ -0,0 +1,75 @@
+package telnetterm
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/internal/remote"
+)
+
+// Server plays recordings from file system to telnet clients. Client chooses recording from menu
+// unless Cast is set.
+type Server struct {
+	// FS contains recordings, all "*.cast" files are listed in menu.
+	FS fs.FS
+
+	// Cast is a name of recording in FS played to every client instead of menu. Connection is closed after playback.
+	Cast string
+
+	// Options for Player.
+	Options []player.Option
+
+	// TermOptions for client terminals.
+	TermOptions []Option
+
+	// ErrorLog logs failed connections. Standard logger is used if nil.
+	ErrorLog *log.Logger
+}
+
+// Serve accepts connections on listener and serves them. It returns error returned by listener.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.ServeConn(conn)
+	}
+}
+
+// ServeConn serves client connection.
+func (s *Server) ServeConn(conn net.Conn) {
+	term, err := New(conn, s.TermOptions...)
+	if err != nil {
+		conn.Close()
+		s.logf("negotiation with %s failed: %s", conn.RemoteAddr(), err)
+
+		return
+	}
+
+	defer term.Close()
+
+	menu := &remote.Menu{FS: s.FS, Options: s.Options}
+
+	if s.Cast == "" {
+		err = menu.Run(term, term.console)
+	} else if err = menu.Play(term, term.console, s.Cast); err != nil {
+		fmt.Fprintf(term, "\033[0m\r\nPlayback failed: %s\r\n", err)
+	}
+
+	if err != nil {
+		s.logf("session of %s failed: %s", conn.RemoteAddr(), err)
+	}
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.ErrorLog != nil {
+		s.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
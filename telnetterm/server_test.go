@@ -0,0 +1,152 @@
+package telnetterm
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// wide cast doesn't fit into default terminal size
+const testCast = `{"version":2,"width":90,"height":5}
+[0.01,"o","hello "]
+[0.02,"o","world"]
+[60,"o","!"]
+`
+
+// client is a test client collecting output of connection.
+type client struct {
+	net.Conn
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	c := &client{Conn: conn}
+
+	go func() {
+		var buf [1024]byte
+
+		for {
+			n, err := conn.Read(buf[:])
+
+			c.mu.Lock()
+			c.buf.Write(buf[:n])
+			c.closed = err != nil
+			c.mu.Unlock()
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// waitFor waits until output after offset contains data and returns offset after it.
+// Empty data waits for connection close.
+func (c *client) waitFor(t *testing.T, offset int, data string) int {
+	t.Helper()
+
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		c.mu.Lock()
+		out, closed := c.buf.String(), c.closed
+		c.mu.Unlock()
+
+		if data == "" && closed {
+			return len(out)
+		}
+
+		if i := strings.Index(out[offset:], data); data != "" && i >= 0 {
+			return offset + i + len(data)
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("%q not found in output %q", data, out[offset:])
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newTestServer(t *testing.T, srv *Server) string {
+	t.Helper()
+
+	srv.FS = fstest.MapFS{"test.cast": {Data: []byte(testCast)}}
+	srv.Options = []player.Option{player.WithMaxWait(time.Second)}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %s", err)
+	}
+
+	t.Cleanup(func() { lis.Close() })
+
+	go srv.Serve(lis)
+
+	return lis.Addr().String()
+}
+
+func TestServer_Telnet(t *testing.T) {
+	// window size ends waiting before timeout
+	c := dial(t, newTestServer(t, &Server{Cast: "test.cast", TermOptions: []Option{WithNegotiationTimeout(10 * time.Second)}}))
+
+	offset := c.waitFor(t, 0, string([]byte{iac, will, optEcho}))
+	offset = c.waitFor(t, offset, string([]byte{iac, do, optNAWS}))
+
+	// terminal type is refused, window size is big enough
+	c.Write([]byte{iac, do, 24, iac, will, optNAWS, iac, sb, optNAWS, 0, 100, 0, 30, iac, se})
+	offset = c.waitFor(t, offset, string([]byte{iac, wont, 24}))
+	offset = c.waitFor(t, offset, "hello world")
+
+	// long pause is skipped by seek, connection is closed after playback
+	c.Write([]byte("\033[C"))
+	offset = c.waitFor(t, offset, "!")
+	c.waitFor(t, offset, "")
+}
+
+func TestServer_Raw(t *testing.T) {
+	c := dial(t, newTestServer(t, &Server{TermOptions: []Option{WithNegotiationTimeout(100 * time.Millisecond)}}))
+
+	offset := c.waitFor(t, 0, "   1) test.cast\r\n")
+
+	// lines are terminated by "\n" without telnet
+	c.Write([]byte("1\n"))
+	offset = c.waitFor(t, offset, player.ErrSmallTerminal.Error()+", 90x5 required")
+
+	c.Write([]byte("q\n"))
+	c.waitFor(t, offset, "")
+}
+
+func TestSubnegotiation(t *testing.T) {
+	oversized := append([]byte{optNAWS}, bytes.Repeat([]byte{1, iac, iac}, 100)...)
+	input := append(oversized, iac, se, optNAWS, 0, 100, 0, iac, iac, iac, se)
+	r := bufio.NewReader(bytes.NewReader(input))
+
+	// oversized data is discarded up to its end
+	if data, err := subnegotiation(r); err != nil || data != nil {
+		t.Fatalf("Oversized subnegotiation not discarded: %v, %v", data, err)
+	}
+
+	if data, err := subnegotiation(r); err != nil || !bytes.Equal(data, []byte{optNAWS, 0, 100, 0, iac}) {
+		t.Fatalf("Unexpected subnegotiation: %v, %v", data, err)
+	}
+}
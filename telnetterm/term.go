@@ -0,0 +1,306 @@
+// Package telnetterm provides player.Terminal working over telnet or raw TCP connection and server playing recordings
+// to its clients.
+//
+// Server negotiates window size (NAWS) and character mode: it echoes input itself, so client turns off local echo
+// and line mode. Clients without telnet support (i.e. netcat) get default window size. Keystrokes control playback:
+// "Space" pauses and continues, "q" or "Ctrl+C" stops, "Left" and "Right" arrows seek backward and forward.
+package telnetterm
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"sync"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/internal/remote"
+)
+
+const (
+	// DefaultWidth is a default width of client terminal without window size negotiation.
+	DefaultWidth = 80
+
+	// DefaultHeight is a default height of client terminal without window size negotiation.
+	DefaultHeight = 24
+
+	// DefaultNegotiationTimeout is a default time limit for receiving window size from client.
+	DefaultNegotiationTimeout = time.Second
+)
+
+// Telnet commands and options (RFC 854, RFC 857, RFC 858, RFC 1073, RFC 856).
+const (
+	se   = 240
+	sb   = 250
+	will = 251
+	wont = 252
+	do   = 253
+	dont = 254
+	iac  = 255
+
+	optBinary = 0
+	optEcho   = 1
+	optSGA    = 3
+	optNAWS   = 31
+)
+
+// maxSubnegotiation is a maximal length of subnegotiation data kept, window size needs only 5 bytes.
+const maxSubnegotiation = 64
+
+type options struct {
+	width, height      int
+	negotiationTimeout time.Duration
+}
+
+// Option for Term.
+type Option func(*options)
+
+// WithDefaultSize sets size of client terminal used when client doesn't report window size.
+// Non-positive values are ignored.
+func WithDefaultSize(width, height int) Option {
+	return func(o *options) {
+		if width > 0 && height > 0 {
+			o.width, o.height = width, height
+		}
+	}
+}
+
+// WithNegotiationTimeout sets time limit for receiving window size from client. Zero or negative values are ignored.
+func WithNegotiationTimeout(t time.Duration) Option {
+	return func(o *options) {
+		if t > 0 {
+			o.negotiationTimeout = t
+		}
+	}
+}
+
+// Term is a player.Terminal working over telnet connection.
+type Term struct {
+	conn    net.Conn
+	input   chan byte // closed when client closes connection
+	console *remote.Console
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	width, height int
+	sized         chan struct{} // closed when window size is received first time
+	sizedOnce     sync.Once
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// New negotiates options with client and waits for its window size within negotiation timeout.
+func New(conn net.Conn, opts ...Option) (*Term, error) {
+	o := options{
+		width:              DefaultWidth,
+		height:             DefaultHeight,
+		negotiationTimeout: DefaultNegotiationTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := &Term{
+		conn:   conn,
+		input:  make(chan byte, 64),
+		width:  o.width,
+		height: o.height,
+		sized:  make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+
+	t.console = &remote.Console{Output: t, Input: t.input, Closed: t.closed}
+
+	err := t.writeRaw([]byte{
+		iac, will, optEcho,
+		iac, will, optSGA,
+		iac, do, optSGA,
+		iac, will, optBinary,
+		iac, do, optNAWS,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go t.readInput()
+
+	timer := time.NewTimer(o.negotiationTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-t.sized:
+	case <-timer.C:
+	}
+
+	return t, nil
+}
+
+// readInput parses telnet commands and passes data to input.
+func (t *Term) readInput() {
+	defer close(t.input)
+
+	r := bufio.NewReader(t.conn)
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+
+		switch {
+		case b == iac:
+			if b, err = t.command(r); err != nil {
+				return
+			}
+
+			if b != iac {
+				continue // escaped IAC is data
+			}
+		case b == '\r':
+			// "CR NUL" and "CR LF" are sent for "Enter"
+			if next, err := r.Peek(1); err == nil && (next[0] == 0 || next[0] == '\n') {
+				r.ReadByte()
+			}
+		}
+
+		select {
+		case t.input <- b:
+		case <-t.closed:
+			return
+		}
+	}
+}
+
+// command handles telnet command after IAC. It returns IAC if it's escaped data byte.
+func (t *Term) command(r *bufio.Reader) (byte, error) {
+	cmd, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch cmd {
+	case iac:
+		return iac, nil
+	case will, wont, do, dont:
+		opt, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		// refuse options that were not offered, answers to offered ones are not needed
+		switch {
+		case cmd == do && opt != optEcho && opt != optSGA && opt != optBinary:
+			t.writeRaw([]byte{iac, wont, opt})
+		case cmd == will && opt != optSGA && opt != optNAWS:
+			t.writeRaw([]byte{iac, dont, opt})
+		}
+	case sb:
+		data, err := subnegotiation(r)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(data) == 5 && data[0] == optNAWS {
+			width, height := int(data[1])<<8|int(data[2]), int(data[3])<<8|int(data[4])
+			if width > 0 && height > 0 {
+				t.mu.Lock()
+				t.width, t.height = width, height
+				t.mu.Unlock()
+
+				t.sizedOnce.Do(func() { close(t.sized) })
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// subnegotiation reads subnegotiation data until "IAC SE", escaped IACs are unescaped.
+// Data of oversized subnegotiation is discarded and nil is returned.
+func subnegotiation(r *bufio.Reader) ([]byte, error) {
+	var (
+		data      []byte
+		oversized bool
+	)
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if b == iac {
+			if b, err = r.ReadByte(); err != nil {
+				return nil, err
+			}
+
+			if b == se {
+				if oversized {
+					return nil, nil
+				}
+
+				return data, nil
+			}
+		}
+
+		if len(data) == maxSubnegotiation {
+			data, oversized = data[:0], true
+		}
+
+		if !oversized {
+			data = append(data, b)
+		}
+	}
+}
+
+// Write sends output to client, IAC bytes are escaped.
+func (t *Term) Write(p []byte) (n int, err error) {
+	if err = t.writeRaw(bytes.ReplaceAll(p, []byte{iac}, []byte{iac, iac})); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (t *Term) writeRaw(p []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	_, err := t.conn.Write(p)
+
+	return err
+}
+
+// Close closes connection.
+func (t *Term) Close() error {
+	var err error
+
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		err = t.conn.Close()
+	})
+
+	return err
+}
+
+// Dimensions returns size of client terminal.
+func (t *Term) Dimensions() (width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.width, t.height
+}
+
+// ToRaw does nothing: character mode is negotiated by New.
+func (t *Term) ToRaw() error { return nil }
+
+// Restore does nothing.
+func (t *Term) Restore() error { return nil }
+
+// Control maps keystrokes of client to playback control until Close. Playback is stopped if client closes connection.
+func (t *Term) Control(control player.PlaybackControl) {
+	t.console.Control(control, t.closed)
+}
@@ -26,8 +26,17 @@ type PlaybackControl interface {
 	// Pause pauses playback. If playback already paused it will continue.
 	Pause()
 
-	// Stop interrupts playback. Must be called once.
+	// Stop interrupts playback. Subsequent calls do nothing.
 	Stop()
 
+	// Seek moves playback to given time in seconds.
+	Seek(seconds float64)
+
+	// SetSpeed changes playback speed. Non-positive values are ignored.
+	SetSpeed(speed float64)
+
+	// Position returns current playback position in seconds.
+	Position() float64
+
 	sealed()
 }
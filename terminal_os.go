@@ -7,11 +7,6 @@ import (
 	"golang.org/x/term"
 )
 
-const (
-	space = 0x20
-	ctrlC = 0x03
-)
-
 var ErrNotTerminal = fmt.Errorf("stdin is not terminal")
 
 // OSTerminal represents terminal on operating system.
@@ -93,6 +88,8 @@ func (t *OSTerminal) Restore() error {
 }
 
 func (t *OSTerminal) Control(control PlaybackControl) {
+	keys := NewKeyControl(control)
+
 	var buf [3]byte // for control sequences beginning with "ESC-["
 	for {
 		select {
@@ -106,15 +103,8 @@ func (t *OSTerminal) Control(control PlaybackControl) {
 			return
 		}
 
-		if n != 1 {
-			continue
-		}
-
-		switch buf[0] {
-		case space:
-			control.Pause()
-		case ctrlC:
-			control.Stop()
+		for _, b := range buf[:n] {
+			keys.Key(b)
 		}
 	}
 }
@@ -0,0 +1,25 @@
+package player
+
+import (
+	"unicode/utf8"
+)
+
+// splitIncompleteUTF8 splits data to part with complete UTF-8 characters and incomplete trailing character.
+// Formats storing raw bytes (i.e. ttyrec) may split characters between records but asciicast frames contain strings.
+func splitIncompleteUTF8(data []byte) (complete, rest []byte) {
+	for i := len(data) - 1; i >= 0 && i > len(data)-utf8.UTFMax; i-- {
+		if data[i] < utf8.RuneSelf {
+			break
+		}
+
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i], data[i:]
+			}
+
+			break
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,378 @@
+package player
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/xakep666/asciinema-player/v3/internal/ansi"
+)
+
+// DefaultMaxGap is a gap between frames in seconds reported as suspicious by default.
+const DefaultMaxGap = 60.
+
+// maxSaneSize is a terminal width or height reported as suspicious.
+const maxSaneSize = 1000
+
+// Severity is a severity of validation problem.
+type Severity string
+
+const (
+	// SeverityError means that recording can't be played correctly.
+	SeverityError Severity = "error"
+
+	// SeverityWarning means that recording is playable but contains something suspicious.
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a problem found in recording during validation.
+type Problem struct {
+	Severity Severity
+
+	// Line is a 1-based line number.
+	Line int
+
+	// Column is a 1-based position of character in line where problem is found.
+	Column int
+
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s", p.Line, p.Column, p.Severity, p.Message)
+}
+
+// Validate checks asciicast v2 recording and returns found problems. Unlike StreamFrameSource it doesn't stop at first error.
+// Header sanity, frame format, known frame types, monotonic timestamps and UTF-8 validity of data are checked.
+// Also negative times, gaps longer than maxGap seconds (DefaultMaxGap if not positive) and
+// escape sequences that resize or retitle host terminal or access clipboard are reported as warnings.
+// Error is returned only if reading failed.
+func Validate(reader io.Reader, maxGap float64) ([]Problem, error) {
+	if maxGap <= 0 {
+		maxGap = DefaultMaxGap
+	}
+
+	v := &validator{maxGap: maxGap}
+
+	br := bufio.NewReader(reader)
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return v.problems, err
+		}
+
+		if len(line) > 0 {
+			v.line++
+			v.validateLine(bytes.TrimRight(line, "\r\n"))
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	if !v.hasHeader {
+		v.report(SeverityError, 1, "missing header")
+	}
+
+	return v.problems, nil
+}
+
+type validator struct {
+	maxGap float64
+
+	problems  []Problem
+	line      int
+	hasHeader bool
+	lastTime  float64
+	parser    ansi.Parser
+}
+
+func (v *validator) validateLine(line []byte) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+
+	if !v.hasHeader {
+		v.hasHeader = true
+		v.validateHeader(line)
+
+		return
+	}
+
+	v.validateFrame(line)
+}
+
+func (v *validator) validateHeader(line []byte) {
+	fields, err := splitJSONObject(line)
+	if err != nil {
+		v.reportJSONError(line, "header", err)
+		return
+	}
+
+	if version, ok := fields["version"]; !ok {
+		v.report(SeverityError, 1, "header: missing version")
+	} else if string(version.raw) != strconv.Itoa(FormatVersion) {
+		v.report(SeverityError, version.column, fmt.Sprintf("header: unsupported version %s", version.raw))
+	}
+
+	for _, name := range []string{"width", "height"} {
+		value, ok := fields[name]
+		if !ok {
+			v.report(SeverityError, 1, fmt.Sprintf("header: missing %s", name))
+			continue
+		}
+
+		size, err := strconv.Atoi(string(value.raw))
+		switch {
+		case err != nil || size <= 0:
+			v.report(SeverityError, value.column, fmt.Sprintf("header: %s must be positive integer, got %s", name, value.raw))
+		case size > maxSaneSize:
+			v.report(SeverityWarning, value.column, fmt.Sprintf("header: %s %d is unusually large", name, size))
+		}
+	}
+
+	if value, ok := fields["timestamp"]; ok {
+		if timestamp, err := strconv.ParseInt(string(value.raw), 10, 64); err != nil || timestamp < 0 {
+			v.report(SeverityError, value.column, fmt.Sprintf("header: invalid timestamp %s", value.raw))
+		}
+	}
+}
+
+func (v *validator) validateFrame(line []byte) {
+	elements, err := splitJSONArray(line)
+	if err != nil {
+		v.reportJSONError(line, "frame", err)
+		return
+	}
+
+	if len(elements) != 3 {
+		v.report(SeverityError, 1, fmt.Sprintf("frame must contain 3 elements, got %d", len(elements)))
+		return
+	}
+
+	rawTime, rawType, rawData := elements[0], elements[1], elements[2]
+
+	var (
+		frameTime float64
+		frameType string
+		data      string
+	)
+
+	if err := json.Unmarshal(rawTime.raw, &frameTime); err != nil {
+		v.report(SeverityError, rawTime.column, fmt.Sprintf("frame time must be number, got %s", rawTime.raw))
+	} else {
+		v.validateTime(rawTime.column, frameTime)
+	}
+
+	if err := json.Unmarshal(rawType.raw, &frameType); err != nil {
+		v.report(SeverityError, rawType.column, fmt.Sprintf("frame type must be string, got %s", rawType.raw))
+		return
+	}
+
+	if err := json.Unmarshal(rawData.raw, &data); err != nil {
+		v.report(SeverityError, rawData.column, fmt.Sprintf("frame data must be string, got %s", rawData.raw))
+		return
+	}
+
+	// json package silently replaces invalid characters so raw data is checked
+	if !utf8.Valid(rawData.raw) {
+		v.report(SeverityError, rawData.column, "frame data is not valid UTF-8")
+	}
+
+	switch FrameType(frameType) {
+	case OutputFrame:
+		v.validateOutput(rawData.column, []byte(data))
+	case ResizeFrame:
+		if _, _, err := ParseResizeData([]byte(data)); err != nil {
+			v.report(SeverityError, rawData.column, err.Error())
+		}
+	case InputFrame, MarkerFrame:
+	default:
+		v.report(SeverityError, rawType.column, fmt.Sprintf("unknown frame type %q", frameType))
+	}
+}
+
+func (v *validator) validateTime(column int, frameTime float64) {
+	switch {
+	case frameTime < 0:
+		v.report(SeverityWarning, column, fmt.Sprintf("negative time %g", frameTime))
+	case frameTime < v.lastTime:
+		v.report(SeverityError, column, fmt.Sprintf("time %g is before time of previous frame %g", frameTime, v.lastTime))
+	case frameTime-v.lastTime > v.maxGap:
+		v.report(SeverityWarning, column, fmt.Sprintf("gap of %gs since previous frame", frameTime-v.lastTime))
+	}
+
+	if frameTime > v.lastTime {
+		v.lastTime = frameTime
+	}
+}
+
+// validateOutput reports escape sequences affecting host terminal. Sequences split between frames are reported on last line.
+func (v *validator) validateOutput(column int, data []byte) {
+	v.parser.Feed(data, func(seq *ansi.Sequence) {
+		switch {
+		case seq.Kind == ansi.CSI && seq.Final == 't' && seq.Prefix == 0 && len(seq.Intermediates) == 0:
+			switch seq.Param(0, 0) {
+			case 8:
+				v.report(SeverityWarning, column,
+					fmt.Sprintf("escape sequence resizes terminal to %dx%d", seq.Param(2, 0), seq.Param(1, 0)))
+			case 22, 23: // save and restore title are harmless
+			default:
+				v.report(SeverityWarning, column, fmt.Sprintf("escape sequence manipulates terminal window (%q)", seq.Raw))
+			}
+		case seq.Kind == ansi.OSC:
+			command := seq.Data
+			if i := bytes.IndexByte(command, ';'); i >= 0 {
+				command = command[:i]
+			}
+
+			switch string(command) {
+			case "0", "1", "2":
+				v.report(SeverityWarning, column, "escape sequence sets terminal title")
+			case "52":
+				v.report(SeverityWarning, column, "escape sequence accesses clipboard")
+			}
+		}
+	})
+}
+
+// reportJSONError reports JSON syntax error at its position.
+func (v *validator) reportJSONError(line []byte, what string, err error) {
+	column := 1
+
+	var (
+		syntaxErr   *json.SyntaxError
+		trailingErr *trailingDataError
+		offset      int64
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &trailingErr):
+		offset = trailingErr.offset
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		offset = int64(len(line)) + 1
+		err = errors.New("unexpected end of line")
+	}
+
+	if offset > 0 && int(offset) <= len(line)+1 {
+		column = utf8.RuneCount(line[:offset-1]) + 1
+	}
+
+	v.report(SeverityError, column, fmt.Sprintf("%s: %s", what, err))
+}
+
+func (v *validator) report(severity Severity, column int, message string) {
+	v.problems = append(v.problems, Problem{Severity: severity, Line: v.line, Column: column, Message: message})
+}
+
+// jsonValue is a raw JSON value with 1-based column of its beginning in line.
+type jsonValue struct {
+	raw    json.RawMessage
+	column int
+}
+
+// splitJSONArray splits line containing JSON array into elements.
+func splitJSONArray(line []byte) ([]jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if err := expectDelim(dec, '[', "JSON array"); err != nil {
+		return nil, err
+	}
+
+	var elements []jsonValue
+	for dec.More() {
+		value, err := decodeJSONValue(dec, line)
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, value)
+	}
+
+	return elements, expectEnd(dec, ']')
+}
+
+// splitJSONObject splits line containing JSON object into fields.
+func splitJSONObject(line []byte) (map[string]jsonValue, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if err := expectDelim(dec, '{', "JSON object"); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]jsonValue{}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := decodeJSONValue(dec, line)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[token.(string)] = value
+	}
+
+	return fields, expectEnd(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim, what string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != delim {
+		return fmt.Errorf("must be %s", what)
+	}
+
+	return nil
+}
+
+func expectEnd(dec *json.Decoder, delim json.Delim) error {
+	if _, err := dec.Token(); err != nil { // closing delimiter, other tokens are rejected by decoder
+		return err
+	}
+
+	_, err := dec.Token()
+
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil
+	case errors.As(err, &syntaxErr):
+		return err
+	default:
+		return &trailingDataError{offset: dec.InputOffset()}
+	}
+}
+
+// trailingDataError returned if line contains something after JSON value.
+type trailingDataError struct {
+	offset int64 // offset after unexpected token
+}
+
+func (e *trailingDataError) Error() string { return "unexpected data after end of value" }
+
+func decodeJSONValue(dec *json.Decoder, line []byte) (jsonValue, error) {
+	// input offset points after previous token, value begins after separators
+	offset := int(dec.InputOffset())
+	for offset < len(line) && bytes.IndexByte([]byte(" \t\r\n,:"), line[offset]) >= 0 {
+		offset++
+	}
+
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return jsonValue{}, err
+	}
+
+	return jsonValue{raw: raw, column: utf8.RuneCount(line[:offset]) + 1}, nil
+}
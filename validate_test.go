@@ -0,0 +1,60 @@
+package player_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+func TestValidate(t *testing.T) {
+	cast := `{"version":2,"width":0,"height":24}
+[1, "o", "a"]
+[0.5, "x", "b"]
+[2, "o", "\u001b]2;title\u0007"]
+[2, "o"
+[3, "o", "a"] x
+[100, "r", "10y"]
+[101, "o", "` + "\xff" + `"]
+
+{}
+`
+
+	problems, err := player.Validate(strings.NewReader(cast), 0)
+	if err != nil {
+		t.Fatalf("Validate failed: %s", err)
+	}
+
+	expected := []player.Problem{
+		{Severity: player.SeverityError, Line: 1, Column: 22, Message: "header: width must be positive integer, got 0"},
+		{Severity: player.SeverityError, Line: 3, Column: 2, Message: "time 0.5 is before time of previous frame 1"},
+		{Severity: player.SeverityError, Line: 3, Column: 7, Message: `unknown frame type "x"`},
+		{Severity: player.SeverityWarning, Line: 4, Column: 10, Message: "escape sequence sets terminal title"},
+		{Severity: player.SeverityError, Line: 5, Column: 7, Message: "frame: unexpected end of JSON input"},
+		{Severity: player.SeverityError, Line: 6, Column: 15, Message: "frame: invalid character 'x' looking for beginning of value"},
+		{Severity: player.SeverityWarning, Line: 7, Column: 2, Message: "gap of 98s since previous frame"},
+		{Severity: player.SeverityError, Line: 7, Column: 12, Message: `invalid resize data "10y"`},
+		{Severity: player.SeverityError, Line: 8, Column: 12, Message: "frame data is not valid UTF-8"},
+		{Severity: player.SeverityError, Line: 10, Column: 1, Message: "frame: must be JSON array"},
+	}
+
+	if !reflect.DeepEqual(expected, problems) {
+		t.Fatalf("Unexpected problems:\n%v", problems)
+	}
+}
+
+func TestValidate_Valid(t *testing.T) {
+	problems, err := player.Validate(strings.NewReader(editTestCast), 0)
+	if err != nil {
+		t.Fatalf("Validate failed: %s", err)
+	}
+
+	if len(problems) != 0 {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+
+	if problems, _ = player.Validate(strings.NewReader(""), 0); len(problems) != 1 || problems[0].Message != "missing header" {
+		t.Fatalf("Unexpected problems: %v", problems)
+	}
+}
@@ -0,0 +1,139 @@
+package vt
+
+import (
+	"strings"
+)
+
+// Color is a terminal color: default, one of 256 indexed colors or 24-bit RGB color.
+type Color uint32
+
+const (
+	colorKindIndexed = 1 << 24
+	colorKindRGB     = 2 << 24
+	colorKindMask    = 0xff << 24
+)
+
+// DefaultColor is a default foreground or background color of terminal.
+const DefaultColor Color = 0
+
+// IndexedColor returns color from 256-color palette. First 16 colors are standard and bright ones.
+func IndexedColor(index uint8) Color { return colorKindIndexed | Color(index) }
+
+// RGBColor returns 24-bit color.
+func RGBColor(r, g, b uint8) Color {
+	return colorKindRGB | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// IsDefault returns true for DefaultColor.
+func (c Color) IsDefault() bool { return c == DefaultColor }
+
+// Index returns palette index of indexed color.
+func (c Color) Index() (index uint8, ok bool) {
+	return uint8(c), c&colorKindMask == colorKindIndexed
+}
+
+// RGB returns components of 24-bit color.
+func (c Color) RGB() (r, g, b uint8, ok bool) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), c&colorKindMask == colorKindRGB
+}
+
+// AttrFlags are text rendition flags.
+type AttrFlags uint16
+
+const (
+	Bold AttrFlags = 1 << iota
+	Faint
+	Italic
+	Underline
+	Blink
+	Inverse
+	Hidden
+	Strikethrough
+)
+
+// Attr is a graphic rendition of cell.
+type Attr struct {
+	FG, BG Color
+	Flags  AttrFlags
+}
+
+// Cell is a character cell of screen.
+type Cell struct {
+	// Rune is a displayed character. It's zero for second cell of wide character.
+	Rune rune
+
+	// Width is 2 for wide character, 0 for cell occupied by previous wide character and 1 otherwise.
+	Width int
+
+	Attr Attr
+}
+
+func blankCell(attr Attr) Cell {
+	return Cell{Rune: ' ', Width: 1, Attr: Attr{BG: attr.BG}}
+}
+
+// Line is a row of screen.
+type Line struct {
+	Cells []Cell
+
+	// Wrapped is true if text continues on next line because it didn't fit into screen width.
+	Wrapped bool
+}
+
+func blankLine(width int, attr Attr) Line {
+	line := Line{Cells: make([]Cell, width)}
+	for i := range line.Cells {
+		line.Cells[i] = blankCell(attr)
+	}
+
+	return line
+}
+
+// String returns text of line without trailing spaces.
+func (l Line) String() string {
+	var sb strings.Builder
+
+	sb.Grow(len(l.Cells))
+
+	for _, cell := range l.Cells {
+		if cell.Width > 0 {
+			sb.WriteRune(cell.Rune)
+		}
+	}
+
+	return strings.TrimRight(sb.String(), " ")
+}
+
+func (l Line) clone() Line {
+	return Line{Cells: append([]Cell(nil), l.Cells...), Wrapped: l.Wrapped}
+}
+
+// JoinWrapped returns text of lines where lines wrapped because of screen width are joined together.
+func JoinWrapped(lines []Line) []string {
+	var (
+		result []string
+		sb     strings.Builder
+	)
+
+	for _, line := range lines {
+		if !line.Wrapped {
+			sb.WriteString(line.String())
+			result = append(result, sb.String())
+			sb.Reset()
+
+			continue
+		}
+
+		for _, cell := range line.Cells {
+			if cell.Width > 0 {
+				sb.WriteRune(cell.Rune)
+			}
+		}
+	}
+
+	if sb.Len() > 0 {
+		result = append(result, strings.TrimRight(sb.String(), " "))
+	}
+
+	return result
+}
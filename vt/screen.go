@@ -0,0 +1,940 @@
+// Package vt implements virtual terminal: a model of screen contents that is updated by terminal output.
+// It's used to get text that was actually visible during playback (for search, export and snapshots).
+// Common xterm subset is supported: cursor movement, erasing, scroll regions, alternate screen, wide characters,
+// colors and text attributes, line drawing character set.
+package vt
+
+import (
+	"bytes"
+
+	"github.com/xakep666/asciinema-player/v3/internal/ansi"
+)
+
+// DefaultScrollback is a default number of lines kept in scrollback.
+const DefaultScrollback = 10000
+
+const tabWidth = 8
+
+// Private modes tracked by Screen. Other private modes are remembered only to be reproduced by Snapshot.
+const (
+	modeOrigin          = 6
+	modeAutowrap        = 7
+	modeCursorVisible   = 25
+	modeAltScreen       = 47
+	modeAltScreenClear  = 1047
+	modeSaveCursor      = 1048
+	modeAltScreenCursor = 1049
+)
+
+type options struct {
+	scrollback int
+	onScroll   func(Line)
+}
+
+// Option for Screen.
+type Option func(*options)
+
+// WithScrollback sets maximal number of lines kept in scrollback. Zero disables scrollback, negative values are ignored.
+func WithScrollback(lines int) Option {
+	return func(o *options) {
+		if lines >= 0 {
+			o.scrollback = lines
+		}
+	}
+}
+
+// WithScrollHandler sets function called for every line scrolled out of main screen (even if scrollback is disabled).
+func WithScrollHandler(fn func(Line)) Option {
+	return func(o *options) {
+		o.onScroll = fn
+	}
+}
+
+type cursor struct {
+	x, y        int
+	attr        Attr
+	pendingWrap bool // cursor is at last column after printing character there
+	origin      bool
+	charsets    [2]bool // true for DEC special graphics in G0 and G1
+	shift       int     // active charset
+}
+
+type buffer struct {
+	lines []Line
+	saved cursor
+}
+
+// Screen is a virtual terminal screen. It implements io.Writer, written data is interpreted as terminal output.
+// Screen is not safe for concurrent use.
+type Screen struct {
+	options options
+
+	width, height int
+	main, alt     *buffer
+	buf           *buffer // active buffer
+	scrollback    []Line
+
+	cursor        cursor
+	top, bottom   int // scroll region
+	tabs          []bool
+	autowrap      bool
+	insert        bool
+	newline       bool // LNM: line feed also moves to first column
+	cursorVisible bool
+	privateModes  map[int]bool
+	title         string
+	lastRune      rune
+
+	parser ansi.Parser
+}
+
+// New constructs Screen with given size.
+func New(width, height int, opts ...Option) *Screen {
+	o := options{scrollback: DefaultScrollback}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &Screen{
+		options: o,
+		width:   max(width, 1),
+		height:  max(height, 1),
+	}
+
+	s.reset()
+
+	return s
+}
+
+func (s *Screen) reset() {
+	s.cursor = cursor{}
+	s.main = &buffer{lines: s.blankLines(s.height)}
+	s.alt = &buffer{lines: s.blankLines(s.height)}
+	s.buf = s.main
+	s.top, s.bottom = 0, s.height-1
+	s.resetTabs()
+	s.autowrap = true
+	s.insert = false
+	s.newline = false
+	s.cursorVisible = true
+	s.privateModes = map[int]bool{}
+	s.lastRune = 0
+}
+
+func (s *Screen) resetTabs() {
+	s.tabs = make([]bool, s.width)
+	for i := tabWidth; i < s.width; i += tabWidth {
+		s.tabs[i] = true
+	}
+}
+
+func (s *Screen) blankLines(n int) []Line {
+	lines := make([]Line, n)
+	for i := range lines {
+		lines[i] = blankLine(s.width, s.cursor.attr)
+	}
+
+	return lines
+}
+
+// Width returns screen width.
+func (s *Screen) Width() int { return s.width }
+
+// Height returns screen height.
+func (s *Screen) Height() int { return s.height }
+
+// Cursor returns cursor position (0-based).
+func (s *Screen) Cursor() (x, y int) { return s.cursor.x, s.cursor.y }
+
+// CursorVisible returns false if cursor was hidden.
+func (s *Screen) CursorVisible() bool { return s.cursorVisible }
+
+// Title returns window title set by output.
+func (s *Screen) Title() string { return s.title }
+
+// AltScreen returns true if alternate screen is active (i.e. full-screen application is running).
+func (s *Screen) AltScreen() bool { return s.buf == s.alt }
+
+// Cell returns cell of active screen.
+func (s *Screen) Cell(x, y int) Cell { return s.buf.lines[y].Cells[x] }
+
+// Line returns copy of active screen row.
+func (s *Screen) Line(y int) Line { return s.buf.lines[y].clone() }
+
+// Lines returns copy of active screen rows.
+func (s *Screen) Lines() []Line {
+	lines := make([]Line, len(s.buf.lines))
+	for i, line := range s.buf.lines {
+		lines[i] = line.clone()
+	}
+
+	return lines
+}
+
+// Scrollback returns lines scrolled out of main screen, oldest first. Returned slice must not be modified.
+func (s *Screen) Scrollback() []Line {
+	if len(s.scrollback) > s.options.scrollback {
+		return s.scrollback[len(s.scrollback)-s.options.scrollback:]
+	}
+
+	return s.scrollback
+}
+
+// Text returns text of active screen, rows are separated by line breaks. Trailing empty rows are omitted.
+func (s *Screen) Text() string {
+	var buf bytes.Buffer
+
+	for i, line := range s.buf.lines {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+
+		buf.WriteString(line.String())
+	}
+
+	return string(bytes.TrimRight(buf.Bytes(), "\n"))
+}
+
+// Write interprets data as terminal output. It never fails.
+func (s *Screen) Write(p []byte) (int, error) {
+	s.parser.Feed(p, s.handle)
+	return len(p), nil
+}
+
+// Resize changes screen size. Rows above cursor are moved to scrollback if height decreases.
+func (s *Screen) Resize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
+	if width == s.width && height == s.height {
+		return
+	}
+
+	for _, buf := range []*buffer{s.main, s.alt} {
+		// keep cursor row on screen
+		if drop := s.cursor.y + 1 - height; drop > 0 {
+			if buf == s.main {
+				for _, line := range buf.lines[:drop] {
+					s.pushScrollback(line)
+				}
+			}
+
+			buf.lines = buf.lines[drop:]
+		}
+
+		if len(buf.lines) > height {
+			buf.lines = buf.lines[:height]
+		}
+
+		for i, line := range buf.lines {
+			buf.lines[i] = resizeLine(line, width)
+		}
+
+		for len(buf.lines) < height {
+			buf.lines = append(buf.lines, blankLine(width, Attr{}))
+		}
+
+		buf.saved.x, buf.saved.y = min(buf.saved.x, width-1), min(buf.saved.y, height-1)
+	}
+
+	if drop := s.cursor.y + 1 - height; drop > 0 {
+		s.cursor.y -= drop
+	}
+
+	s.width, s.height = width, height
+	s.top, s.bottom = 0, height-1
+	s.cursor.x, s.cursor.y = min(s.cursor.x, width-1), min(s.cursor.y, height-1)
+	s.cursor.pendingWrap = false
+	s.resetTabs()
+}
+
+func resizeLine(line Line, width int) Line {
+	if len(line.Cells) >= width {
+		line.Cells = line.Cells[:width]
+		if last := &line.Cells[width-1]; last.Width == 2 {
+			*last = blankCell(last.Attr)
+		}
+
+		return line
+	}
+
+	for len(line.Cells) < width {
+		line.Cells = append(line.Cells, blankCell(Attr{}))
+	}
+
+	line.Wrapped = false
+
+	return line
+}
+
+func (s *Screen) pushScrollback(line Line) {
+	if s.options.onScroll != nil {
+		s.options.onScroll(line.clone())
+	}
+
+	if s.options.scrollback == 0 {
+		return
+	}
+
+	s.scrollback = append(s.scrollback, line)
+
+	// old lines are dropped in batches, Scrollback returns only last lines within limit
+	if len(s.scrollback) >= 2*s.options.scrollback {
+		s.scrollback = append([]Line(nil), s.scrollback[len(s.scrollback)-s.options.scrollback:]...)
+	}
+}
+
+func (s *Screen) handle(seq *ansi.Sequence) {
+	switch seq.Kind {
+	case ansi.Print:
+		s.print(seq.Rune)
+	case ansi.Control:
+		s.control(seq.Byte)
+	case ansi.ESC:
+		s.escape(seq)
+	case ansi.CSI:
+		s.csi(seq)
+	case ansi.OSC:
+		s.osc(seq.Data)
+	}
+}
+
+func (s *Screen) print(r rune) {
+	if s.cursor.charsets[s.cursor.shift] && r >= '`' && r <= '~' {
+		r = decGraphics[r-'`']
+	}
+
+	width := runeWidth(r)
+	if width == 0 {
+		return // combining characters are not supported
+	}
+
+	s.lastRune = r
+
+	if s.cursor.pendingWrap && s.autowrap {
+		s.wrap()
+	}
+
+	if width == 2 && s.cursor.x == s.width-1 {
+		if s.width == 1 {
+			return
+		}
+
+		if s.autowrap {
+			s.buf.lines[s.cursor.y].Cells[s.cursor.x] = blankCell(s.cursor.attr)
+			s.wrap()
+		} else {
+			s.cursor.x--
+		}
+	}
+
+	line := s.buf.lines[s.cursor.y].Cells
+	if s.insert {
+		copy(line[s.cursor.x+width:], line[s.cursor.x:])
+	}
+
+	s.clearWide(s.cursor.x, s.cursor.x+width)
+
+	line[s.cursor.x] = Cell{Rune: r, Width: width, Attr: s.cursor.attr}
+	if width == 2 {
+		line[s.cursor.x+1] = Cell{Width: 0, Attr: s.cursor.attr}
+	}
+
+	if s.cursor.x+width < s.width {
+		s.cursor.x += width
+	} else {
+		s.cursor.x = s.width - 1
+		s.cursor.pendingWrap = true
+	}
+}
+
+// wrap moves cursor to beginning of next line marking current one as wrapped.
+func (s *Screen) wrap() {
+	s.buf.lines[s.cursor.y].Wrapped = true
+	s.cursor.x = 0
+	s.cursor.pendingWrap = false
+	s.index()
+}
+
+// clearWide blanks halves of wide characters partially overwritten in range [from, to) of cursor row.
+func (s *Screen) clearWide(from, to int) {
+	line := s.buf.lines[s.cursor.y].Cells
+
+	if from > 0 && from < s.width && line[from].Width == 0 {
+		line[from-1] = blankCell(line[from-1].Attr)
+	}
+
+	if to > 0 && to < s.width && line[to].Width == 0 {
+		line[to] = blankCell(line[to].Attr)
+	}
+}
+
+func (s *Screen) control(b byte) {
+	switch b {
+	case '\b':
+		if s.cursor.x > 0 {
+			s.cursor.x--
+		}
+	case '\t':
+		s.tab(1)
+		return // tab keeps pending wrap in xterm
+	case '\n', '\v', '\f':
+		s.index()
+		if s.newline {
+			s.cursor.x = 0
+		}
+	case '\r':
+		s.cursor.x = 0
+	case 0x0e: // SO
+		s.cursor.shift = 1
+		return
+	case 0x0f: // SI
+		s.cursor.shift = 0
+		return
+	default:
+		return
+	}
+
+	s.cursor.pendingWrap = false
+}
+
+func (s *Screen) tab(n int) {
+	for ; n > 0 && s.cursor.x < s.width-1; n-- {
+		s.cursor.x++
+		for s.cursor.x < s.width-1 && !s.tabs[s.cursor.x] {
+			s.cursor.x++
+		}
+	}
+}
+
+func (s *Screen) backTab(n int) {
+	for ; n > 0 && s.cursor.x > 0; n-- {
+		s.cursor.x--
+		for s.cursor.x > 0 && !s.tabs[s.cursor.x] {
+			s.cursor.x--
+		}
+	}
+}
+
+// index moves cursor down scrolling region if cursor is at its bottom.
+func (s *Screen) index() {
+	switch {
+	case s.cursor.y == s.bottom:
+		s.scrollUp(1)
+	case s.cursor.y < s.height-1:
+		s.cursor.y++
+	}
+}
+
+// reverseIndex moves cursor up scrolling region if cursor is at its top.
+func (s *Screen) reverseIndex() {
+	switch {
+	case s.cursor.y == s.top:
+		s.scrollDown(1)
+	case s.cursor.y > 0:
+		s.cursor.y--
+	}
+}
+
+// scrollUp scrolls region up, lines scrolled out of full-height region of main screen go to scrollback.
+func (s *Screen) scrollUp(n int) {
+	n = min(n, s.bottom-s.top+1)
+	lines := s.buf.lines
+
+	for i := s.top; i < s.top+n; i++ {
+		if s.buf == s.main && s.top == 0 {
+			s.pushScrollback(lines[i])
+		}
+	}
+
+	copy(lines[s.top:], lines[s.top+n:s.bottom+1])
+
+	for i := s.bottom - n + 1; i <= s.bottom; i++ {
+		lines[i] = blankLine(s.width, s.cursor.attr)
+	}
+}
+
+func (s *Screen) scrollDown(n int) {
+	n = min(n, s.bottom-s.top+1)
+	lines := s.buf.lines
+
+	copy(lines[s.top+n:s.bottom+1], lines[s.top:])
+
+	for i := s.top; i < s.top+n; i++ {
+		lines[i] = blankLine(s.width, s.cursor.attr)
+	}
+}
+
+func (s *Screen) escape(seq *ansi.Sequence) {
+	if len(seq.Intermediates) > 0 {
+		switch seq.Intermediates[0] {
+		case '(', ')':
+			s.cursor.charsets[seq.Intermediates[0]-'('] = seq.Final == '0'
+		case '#':
+			if seq.Final == '8' { // DECALN
+				for _, line := range s.buf.lines {
+					for i := range line.Cells {
+						line.Cells[i] = Cell{Rune: 'E', Width: 1}
+					}
+				}
+			}
+		}
+
+		return
+	}
+
+	switch seq.Final {
+	case '7':
+		s.saveCursor()
+	case '8':
+		s.restoreCursor()
+	case 'D':
+		s.index()
+	case 'E':
+		s.cursor.x = 0
+		s.index()
+	case 'M':
+		s.reverseIndex()
+	case 'H':
+		s.tabs[s.cursor.x] = true
+	case 'c':
+		s.reset()
+		return
+	default:
+		return
+	}
+
+	s.cursor.pendingWrap = false
+}
+
+func (s *Screen) saveCursor() { s.buf.saved = s.cursor }
+
+func (s *Screen) restoreCursor() {
+	s.cursor = s.buf.saved
+	s.cursor.x, s.cursor.y = min(s.cursor.x, s.width-1), min(s.cursor.y, s.height-1)
+}
+
+func (s *Screen) csi(seq *ansi.Sequence) {
+	switch {
+	case seq.Prefix == '?':
+		s.privateCSI(seq)
+		return
+	case seq.Prefix != 0:
+		return
+	case len(seq.Intermediates) > 0:
+		if string(seq.Intermediates) == "!" && seq.Final == 'p' { // DECSTR
+			s.softReset()
+		}
+
+		return
+	}
+
+	n := seq.Param(0, 1)
+
+	switch seq.Final {
+	case '@': // ICH
+		line := s.buf.lines[s.cursor.y].Cells
+		n = min(n, s.width-s.cursor.x)
+		s.clearWide(s.cursor.x, s.cursor.x)
+		copy(line[s.cursor.x+n:], line[s.cursor.x:])
+		s.erase(s.cursor.y, s.cursor.x, s.cursor.x+n)
+	case 'A': // CUU
+		s.moveTo(s.cursor.x, max(s.cursor.y-n, s.regionTop()))
+	case 'B', 'e': // CUD, VPR
+		s.moveTo(s.cursor.x, min(s.cursor.y+n, s.regionBottom()))
+	case 'C', 'a': // CUF, HPR
+		s.moveTo(s.cursor.x+n, s.cursor.y)
+	case 'D': // CUB
+		s.moveTo(s.cursor.x-n, s.cursor.y)
+	case 'E': // CNL
+		s.moveTo(0, min(s.cursor.y+n, s.regionBottom()))
+	case 'F': // CPL
+		s.moveTo(0, max(s.cursor.y-n, s.regionTop()))
+	case 'G', '`': // CHA, HPA
+		s.moveTo(n-1, s.cursor.y)
+	case 'H', 'f': // CUP, HVP
+		s.moveToOrigin(seq.Param(1, 1)-1, n-1)
+	case 'I': // CHT
+		s.tab(n)
+	case 'J': // ED
+		s.eraseDisplay(seq.Param(0, 0))
+	case 'K': // EL
+		s.eraseLine(seq.Param(0, 0))
+	case 'L': // IL
+		s.insertLines(n)
+	case 'M': // DL
+		s.deleteLines(n)
+	case 'P': // DCH
+		line := s.buf.lines[s.cursor.y].Cells
+		n = min(n, s.width-s.cursor.x)
+		s.clearWide(s.cursor.x, s.cursor.x+n)
+		copy(line[s.cursor.x:], line[s.cursor.x+n:])
+		s.erase(s.cursor.y, s.width-n, s.width)
+	case 'S': // SU
+		s.scrollUp(n)
+	case 'T': // SD
+		if len(seq.Params) <= 1 {
+			s.scrollDown(n)
+		}
+	case 'X': // ECH
+		s.clearWide(s.cursor.x, s.cursor.x+n)
+		s.erase(s.cursor.y, s.cursor.x, min(s.cursor.x+n, s.width))
+	case 'Z': // CBT
+		s.backTab(n)
+	case 'b': // REP
+		if s.lastRune != 0 {
+			for i := 0; i < min(n, s.width*s.height); i++ {
+				s.print(s.lastRune)
+			}
+		}
+	case 'd': // VPA
+		s.moveToOrigin(s.cursor.x, n-1)
+	case 'g': // TBC
+		switch seq.Param(0, 0) {
+		case 0:
+			s.tabs[s.cursor.x] = false
+		case 3:
+			s.tabs = make([]bool, s.width)
+		}
+	case 'h', 'l': // SM, RM
+		for _, mode := range seq.Params {
+			switch mode {
+			case 4:
+				s.insert = seq.Final == 'h'
+			case 20:
+				s.newline = seq.Final == 'h'
+			}
+		}
+	case 'm':
+		s.sgr(seq.Params)
+	case 'r': // DECSTBM
+		top, bottom := seq.Param(0, 1)-1, seq.Param(1, s.height)-1
+		if bottom >= s.height {
+			bottom = s.height - 1
+		}
+
+		if top < bottom {
+			s.top, s.bottom = top, bottom
+			s.moveToOrigin(0, 0)
+		}
+	case 's': // SCOSC
+		if len(seq.Params) == 0 {
+			s.saveCursor()
+		}
+	case 'u': // SCORC
+		s.restoreCursor()
+	}
+}
+
+func (s *Screen) privateCSI(seq *ansi.Sequence) {
+	switch seq.Final {
+	case 'h', 'l':
+		for _, mode := range seq.Params {
+			s.setPrivateMode(mode, seq.Final == 'h')
+		}
+	case 'J': // DECSED
+		s.eraseDisplay(seq.Param(0, 0))
+	case 'K': // DECSEL
+		s.eraseLine(seq.Param(0, 0))
+	}
+}
+
+func (s *Screen) setPrivateMode(mode int, set bool) {
+	switch mode {
+	case modeOrigin:
+		s.cursor.origin = set
+		s.moveToOrigin(0, 0)
+	case modeAutowrap:
+		s.autowrap = set
+	case modeCursorVisible:
+		s.cursorVisible = set
+	case modeAltScreen, modeAltScreenClear, modeAltScreenCursor:
+		if set == s.AltScreen() {
+			return
+		}
+
+		if set {
+			if mode == modeAltScreenCursor {
+				s.saveCursor()
+			}
+
+			s.buf = s.alt
+			if mode != modeAltScreen {
+				s.alt.lines = s.blankLines(s.height)
+			}
+		} else {
+			s.buf = s.main
+			if mode == modeAltScreenCursor {
+				s.restoreCursor()
+			}
+		}
+	case modeSaveCursor:
+		if set {
+			s.saveCursor()
+		} else {
+			s.restoreCursor()
+		}
+	default:
+		if set {
+			s.privateModes[mode] = true
+		} else {
+			delete(s.privateModes, mode)
+		}
+	}
+}
+
+func (s *Screen) softReset() {
+	s.cursorVisible = true
+	s.insert = false
+	s.autowrap = true
+	s.cursor.origin = false
+	s.cursor.attr = Attr{}
+	s.cursor.charsets = [2]bool{}
+	s.cursor.shift = 0
+	s.top, s.bottom = 0, s.height-1
+	s.buf.saved = cursor{}
+}
+
+func (s *Screen) regionTop() int {
+	if s.cursor.y < s.top {
+		return 0
+	}
+
+	return s.top
+}
+
+func (s *Screen) regionBottom() int {
+	if s.cursor.y > s.bottom {
+		return s.height - 1
+	}
+
+	return s.bottom
+}
+
+func (s *Screen) moveTo(x, y int) {
+	s.cursor.x = max(min(x, s.width-1), 0)
+	s.cursor.y = max(min(y, s.height-1), 0)
+	s.cursor.pendingWrap = false
+}
+
+// moveToOrigin moves cursor to position relative to scroll region if origin mode is set.
+func (s *Screen) moveToOrigin(x, y int) {
+	if s.cursor.origin {
+		s.moveTo(x, min(y+s.top, s.bottom))
+		return
+	}
+
+	s.moveTo(x, y)
+}
+
+// erase blanks cells [from, to) of row.
+func (s *Screen) erase(y, from, to int) {
+	line := s.buf.lines[y].Cells
+	for i := max(from, 0); i < to && i < s.width; i++ {
+		line[i] = blankCell(s.cursor.attr)
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.cursor.y + 1; y < s.height; y++ {
+			s.buf.lines[y] = blankLine(s.width, s.cursor.attr)
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.cursor.y; y++ {
+			s.buf.lines[y] = blankLine(s.width, s.cursor.attr)
+		}
+	case 2:
+		// like many terminal emulators keep content of cleared main screen in scrollback
+		if s.buf == s.main {
+			last := len(s.buf.lines) - 1
+			for last >= 0 && s.buf.lines[last].String() == "" {
+				last--
+			}
+
+			for _, line := range s.buf.lines[:last+1] {
+				s.pushScrollback(line)
+			}
+		}
+
+		for y := range s.buf.lines {
+			s.buf.lines[y] = blankLine(s.width, s.cursor.attr)
+		}
+	case 3:
+		s.scrollback = nil
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		s.clearWide(s.cursor.x, s.cursor.x)
+		s.erase(s.cursor.y, s.cursor.x, s.width)
+		s.buf.lines[s.cursor.y].Wrapped = false
+	case 1:
+		s.clearWide(s.cursor.x+1, s.cursor.x+1)
+		s.erase(s.cursor.y, 0, s.cursor.x+1)
+	case 2:
+		s.buf.lines[s.cursor.y] = blankLine(s.width, s.cursor.attr)
+	}
+}
+
+func (s *Screen) insertLines(n int) {
+	if s.cursor.y < s.top || s.cursor.y > s.bottom {
+		return
+	}
+
+	top := s.top
+	s.top = s.cursor.y
+	s.scrollDown(n)
+	s.top = top
+	s.cursor.x = 0
+}
+
+func (s *Screen) deleteLines(n int) {
+	if s.cursor.y < s.top || s.cursor.y > s.bottom {
+		return
+	}
+
+	top := s.top
+	s.top = s.cursor.y
+	n = min(n, s.bottom-s.top+1)
+
+	// deleted lines are not scrolled into scrollback
+	lines := s.buf.lines
+	copy(lines[s.top:], lines[s.top+n:s.bottom+1])
+
+	for i := s.bottom - n + 1; i <= s.bottom; i++ {
+		lines[i] = blankLine(s.width, s.cursor.attr)
+	}
+
+	s.top = top
+	s.cursor.x = 0
+}
+
+func (s *Screen) sgr(params []int) {
+	if len(params) == 0 {
+		params = []int{0}
+	}
+
+	attr := &s.cursor.attr
+
+	for i := 0; i < len(params); i++ {
+		switch p := params[i]; {
+		case p == 0:
+			*attr = Attr{}
+		case p == 1:
+			attr.Flags |= Bold
+		case p == 2:
+			attr.Flags |= Faint
+		case p == 3:
+			attr.Flags |= Italic
+		case p == 4 || p == 21:
+			attr.Flags |= Underline
+		case p == 5 || p == 6:
+			attr.Flags |= Blink
+		case p == 7:
+			attr.Flags |= Inverse
+		case p == 8:
+			attr.Flags |= Hidden
+		case p == 9:
+			attr.Flags |= Strikethrough
+		case p == 22:
+			attr.Flags &^= Bold | Faint
+		case p == 23:
+			attr.Flags &^= Italic
+		case p == 24:
+			attr.Flags &^= Underline
+		case p == 25:
+			attr.Flags &^= Blink
+		case p == 27:
+			attr.Flags &^= Inverse
+		case p == 28:
+			attr.Flags &^= Hidden
+		case p == 29:
+			attr.Flags &^= Strikethrough
+		case p >= 30 && p <= 37:
+			attr.FG = IndexedColor(uint8(p - 30))
+		case p == 38 || p == 48:
+			color, consumed := extendedColor(params[i+1:])
+			i += consumed
+
+			if consumed > 0 {
+				if p == 38 {
+					attr.FG = color
+				} else {
+					attr.BG = color
+				}
+			}
+		case p == 39:
+			attr.FG = DefaultColor
+		case p >= 40 && p <= 47:
+			attr.BG = IndexedColor(uint8(p - 40))
+		case p == 49:
+			attr.BG = DefaultColor
+		case p >= 90 && p <= 97:
+			attr.FG = IndexedColor(uint8(p - 90 + 8))
+		case p >= 100 && p <= 107:
+			attr.BG = IndexedColor(uint8(p - 100 + 8))
+		}
+	}
+}
+
+// extendedColor parses "5;n" or "2;r;g;b" following 38 or 48 and returns number of consumed params.
+func extendedColor(params []int) (Color, int) {
+	if len(params) == 0 {
+		return DefaultColor, 0
+	}
+
+	switch params[0] {
+	case 5:
+		if len(params) < 2 {
+			return DefaultColor, len(params)
+		}
+
+		return IndexedColor(uint8(params[1])), 2
+	case 2:
+		if len(params) < 4 {
+			return DefaultColor, len(params)
+		}
+
+		return RGBColor(uint8(params[1]), uint8(params[2]), uint8(params[3])), 4
+	default:
+		return DefaultColor, 0
+	}
+}
+
+func (s *Screen) osc(data []byte) {
+	command, arg := data, []byte(nil)
+	if i := bytes.IndexByte(data, ';'); i >= 0 {
+		command, arg = data[:i], data[i+1:]
+	}
+
+	switch string(command) {
+	case "0", "2":
+		s.title = string(arg)
+	}
+}
+
+// decGraphics maps characters from '`' to '~' in DEC special graphics character set.
+var decGraphics = [...]rune{
+	'◆', '▒', '␉', '␌', '␍', '␊', '°', '±', '␤', '␋', '┘', '┐', '┌', '└', '┼', '⎺',
+	'⎻', '─', '⎼', '⎽', '├', '┤', '┴', '┬', '│', '≤', '≥', 'π', '≠', '£', '·',
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
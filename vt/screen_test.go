@@ -0,0 +1,139 @@
+package vt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func screenText(s *Screen) []string {
+	var lines []string
+	for _, line := range s.Lines() {
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		cursorX  int
+		cursorY  int
+	}{
+		{name: "print", input: "hello\r\nworld", expected: []string{"hello", "world", "", ""}, cursorX: 5, cursorY: 1},
+		{name: "wrap", input: "0123456789ab", expected: []string{"0123456789", "ab", "", ""}, cursorX: 2, cursorY: 1},
+		{name: "pending wrap", input: "0123456789\r\nx", expected: []string{"0123456789", "x", "", ""}, cursorX: 1, cursorY: 1},
+		{name: "no autowrap", input: "\x1b[?7l0123456789ab", expected: []string{"012345678b", "", "", ""}, cursorX: 9},
+		{name: "progress bar", input: "10%\r50%\r100%", expected: []string{"100%", "", "", ""}, cursorX: 4},
+		{name: "backspace", input: "abc\b\bX", expected: []string{"aXc", "", "", ""}, cursorX: 2},
+		{name: "tab", input: "a\tb", expected: []string{"a       b", "", "", ""}, cursorX: 9},
+		{name: "cursor position", input: "\x1b[3;5Hx\x1b[1;1Hy", expected: []string{"y", "", "    x", ""}, cursorX: 1},
+		{name: "erase line", input: "abcdef\x1b[3D\x1b[K", expected: []string{"abc", "", "", ""}, cursorX: 3},
+		{name: "erase display", input: "a\r\nb\r\nc\x1b[2J", expected: []string{"", "", "", ""}, cursorX: 1, cursorY: 2},
+		{name: "scroll", input: "1\r\n2\r\n3\r\n4\r\n5", expected: []string{"2", "3", "4", "5"}, cursorX: 1, cursorY: 3},
+		{name: "scroll region", input: "1\r\n2\r\n3\r\n4\x1b[2;3r\x1b[3;1H\n", expected: []string{"1", "3", "", "4"}, cursorY: 2},
+		{name: "insert and delete chars", input: "abcd\x1b[1;2H\x1b[2@\x1b[1;5H\x1b[P", expected: []string{"a  bd", "", "", ""}, cursorX: 4},
+		{name: "insert and delete lines", input: "1\r\n2\r\n3\x1b[2;1H\x1b[L\x1b[4;1H\x1b[M", expected: []string{"1", "", "2", ""}, cursorY: 3},
+		{name: "wide characters", input: "世界12345678", expected: []string{"世界123456", "78", "", ""}, cursorX: 2, cursorY: 1},
+		{name: "wide character wrap", input: "123456789世", expected: []string{"123456789", "世", "", ""}, cursorX: 2, cursorY: 1},
+		{name: "overwrite wide character", input: "世\rx", expected: []string{"x", "", "", ""}, cursorX: 1},
+		{name: "line drawing", input: "\x1b(0lqk\x1b(Bx", expected: []string{"┌─┐x", "", "", ""}, cursorX: 4},
+		{name: "repeat", input: "-\x1b[4b", expected: []string{"-----", "", "", ""}, cursorX: 5},
+		{name: "reverse index", input: "1\r\n2\x1b[H\x1bM", expected: []string{"", "1", "2", ""}},
+		{name: "save and restore cursor", input: "ab\x1b7\r\ncd\x1b8e", expected: []string{"abe", "cd", "", ""}, cursorX: 3},
+		{name: "reset", input: "abc\x1bc", expected: []string{"", "", "", ""}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			s := New(10, 4)
+			s.Write([]byte(test.input))
+
+			if actual := screenText(s); !reflect.DeepEqual(test.expected, actual) {
+				t.Fatalf("Unexpected screen: %q", actual)
+			}
+
+			if x, y := s.Cursor(); x != test.cursorX || y != test.cursorY {
+				t.Fatalf("Unexpected cursor: %d, %d", x, y)
+			}
+		})
+	}
+}
+
+func TestScreen_Scrollback(t *testing.T) {
+	var scrolled []string
+
+	s := New(10, 2, WithScrollback(2), WithScrollHandler(func(line Line) { scrolled = append(scrolled, line.String()) }))
+	s.Write([]byte("1\r\n2\r\n3\r\n4\r\n5"))
+
+	if !reflect.DeepEqual([]string{"1", "2", "3"}, scrolled) {
+		t.Fatalf("Unexpected scrolled lines: %q", scrolled)
+	}
+
+	var scrollback []string
+	for _, line := range s.Scrollback() {
+		scrollback = append(scrollback, line.String())
+	}
+
+	if !reflect.DeepEqual([]string{"2", "3"}, scrollback) {
+		t.Fatalf("Unexpected scrollback: %q", scrollback)
+	}
+
+	// alternate screen doesn't affect main screen and scrollback
+	s.Write([]byte("\x1b[?1049h\x1b[Hfull\r\nscreen\r\napp\x1b[?1049l"))
+
+	if s.Text() != "4\n5" || len(s.Scrollback()) != 2 {
+		t.Fatalf("Unexpected screen after alternate screen: %q", s.Text())
+	}
+
+	// cleared content of main screen goes to scrollback
+	scrolled = nil
+	s = New(10, 3, WithScrollHandler(func(line Line) { scrolled = append(scrolled, line.String()) }))
+	s.Write([]byte("1\r\n2\x1b[H\x1b[2J"))
+
+	if !reflect.DeepEqual([]string{"1", "2"}, scrolled) || s.Text() != "" {
+		t.Fatalf("Unexpected scrolled lines after clear: %q", scrolled)
+	}
+}
+
+func TestScreen_Attributes(t *testing.T) {
+	s := New(10, 2)
+	s.Write([]byte("\x1b[1;31ma\x1b[38;5;100;48;2;1;2;3mb\x1b[0mc\x1b]0;title\x07"))
+
+	expected := []Attr{
+		{FG: IndexedColor(1), Flags: Bold},
+		{FG: IndexedColor(100), BG: RGBColor(1, 2, 3), Flags: Bold},
+		{},
+	}
+
+	for i, attr := range expected {
+		if actual := s.Cell(i, 0).Attr; actual != attr {
+			t.Fatalf("Unexpected attributes of cell %d: %+v", i, actual)
+		}
+	}
+
+	if s.Title() != "title" {
+		t.Fatalf("Unexpected title: %q", s.Title())
+	}
+}
+
+func TestScreen_Resize(t *testing.T) {
+	s := New(10, 4)
+	s.Write([]byte("1\r\n2\r\n3\r\n4"))
+	s.Resize(5, 2)
+
+	if s.Text() != "3\n4" || len(s.Scrollback()) != 2 {
+		t.Fatalf("Unexpected screen: %q", s.Text())
+	}
+
+	s.Resize(20, 3)
+	s.Write([]byte(strings.Repeat("x", 15)))
+
+	if s.Text() != "3\n4"+strings.Repeat("x", 15) {
+		t.Fatalf("Unexpected screen: %q", s.Text())
+	}
+}
@@ -0,0 +1,211 @@
+package vt
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+)
+
+// Snapshot returns terminal output reproducing current state of screen on terminal of the same size:
+// content of main and alternate screen, text attributes, cursor, scroll region, modes and title.
+// Output starts with terminal reset, scrollback is not included.
+func (s *Screen) Snapshot() []byte {
+	var b bytes.Buffer
+
+	b.WriteString("\x1bc")
+
+	if s.title != "" {
+		b.WriteString("\x1b]2;" + s.title + "\x07")
+	}
+
+	s.writeLines(&b, s.main.lines)
+
+	if s.AltScreen() {
+		// entering alternate screen saves cursor to restore on exit
+		writeCursor(&b, s.main.saved)
+		b.WriteString("\x1b[?1049h")
+		s.writeLines(&b, s.alt.lines)
+	}
+
+	if saved := s.buf.saved; saved != (cursor{}) {
+		writeCursor(&b, saved)
+		b.WriteString("\x1b7")
+	}
+
+	if s.top != 0 || s.bottom != s.height-1 {
+		b.WriteString("\x1b[" + strconv.Itoa(s.top+1) + ";" + strconv.Itoa(s.bottom+1) + "r")
+	}
+
+	modes := make([]int, 0, len(s.privateModes))
+	for mode := range s.privateModes {
+		modes = append(modes, mode)
+	}
+
+	sort.Ints(modes)
+
+	for _, mode := range modes {
+		b.WriteString("\x1b[?" + strconv.Itoa(mode) + "h")
+	}
+
+	if s.newline {
+		b.WriteString("\x1b[20h")
+	}
+
+	if !s.cursorVisible {
+		b.WriteString("\x1b[?25l")
+	}
+
+	c := s.cursor
+	if c.origin {
+		// origin mode makes cursor position relative to scroll region
+		b.WriteString("\x1b[?6h")
+		b.WriteString(cup(c.x, c.y-s.top))
+	} else {
+		b.WriteString(cup(c.x, c.y))
+	}
+
+	if c.pendingWrap {
+		// reprint last character to get cursor waiting for wrap
+		cell := s.buf.lines[c.y].Cells[c.x]
+		b.WriteString(sgr(cell.Attr))
+		b.WriteRune(cell.Rune)
+	}
+
+	writePen(&b, c)
+
+	if s.insert {
+		b.WriteString("\x1b[4h")
+	}
+
+	if !s.autowrap {
+		b.WriteString("\x1b[?7l")
+	}
+
+	return b.Bytes()
+}
+
+// writeLines writes content of lines. Wrapped line is written up to last column and next one continues it
+// without cursor movement, so wrapping is reproduced.
+func (s *Screen) writeLines(b *bytes.Buffer, lines []Line) {
+	continued := false // previous line is wrapped, cursor waits for wrap at its end
+
+	for y, line := range lines {
+		// trailing blank cells are left as they are after reset
+		end := len(line.Cells)
+		if !line.Wrapped {
+			for end > 0 && line.Cells[end-1] == blankCell(Attr{}) {
+				end--
+			}
+		}
+
+		if continued && end == 0 {
+			end = 1 // printed character makes wrap
+		}
+
+		if end == 0 {
+			continue
+		}
+
+		if !continued {
+			b.WriteString(cup(0, y))
+		}
+
+		attr := Attr{}
+		b.WriteString(sgr(attr))
+
+		for _, cell := range line.Cells[:end] {
+			if cell.Width == 0 {
+				continue
+			}
+
+			if cell.Attr != attr {
+				attr = cell.Attr
+				b.WriteString(sgr(attr))
+			}
+
+			b.WriteRune(cell.Rune)
+		}
+
+		continued = line.Wrapped
+	}
+
+	b.WriteString(sgr(Attr{}))
+}
+
+// writeCursor writes cursor position, attributes and character sets.
+func writeCursor(b *bytes.Buffer, c cursor) {
+	b.WriteString(cup(c.x, c.y))
+	writePen(b, c)
+}
+
+// writePen writes cursor attributes and character sets.
+func writePen(b *bytes.Buffer, c cursor) {
+	b.WriteString(sgr(c.attr))
+
+	for i, designator := range []string{"\x1b(", "\x1b)"} {
+		if c.charsets[i] {
+			b.WriteString(designator + "0")
+		} else {
+			b.WriteString(designator + "B")
+		}
+	}
+
+	if c.shift == 1 {
+		b.WriteByte('\x0e')
+	} else {
+		b.WriteByte('\x0f')
+	}
+}
+
+func cup(x, y int) string {
+	return "\x1b[" + strconv.Itoa(y+1) + ";" + strconv.Itoa(x+1) + "H"
+}
+
+// sgr returns sequence setting given attributes.
+func sgr(attr Attr) string {
+	params := []string{"0"}
+
+	for i, flag := range []AttrFlags{Bold, Faint, Italic, Underline, Blink, Inverse, Hidden, Strikethrough} {
+		if attr.Flags&flag != 0 {
+			params = append(params, strconv.Itoa([]int{1, 2, 3, 4, 5, 7, 8, 9}[i]))
+		}
+	}
+
+	params = append(params, colorParams(attr.FG, 30, 90, 38)...)
+	params = append(params, colorParams(attr.BG, 40, 100, 48)...)
+
+	var b bytes.Buffer
+
+	b.WriteString("\x1b[")
+
+	for i, param := range params {
+		if i > 0 {
+			b.WriteByte(';')
+		}
+
+		b.WriteString(param)
+	}
+
+	b.WriteByte('m')
+
+	return b.String()
+}
+
+func colorParams(c Color, base, bright, extended int) []string {
+	if index, ok := c.Index(); ok {
+		switch {
+		case index < 8:
+			return []string{strconv.Itoa(base + int(index))}
+		case index < 16:
+			return []string{strconv.Itoa(bright + int(index) - 8)}
+		default:
+			return []string{strconv.Itoa(extended), "5", strconv.Itoa(int(index))}
+		}
+	}
+
+	if r, g, b, ok := c.RGB(); ok {
+		return []string{strconv.Itoa(extended), "2", strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b))}
+	}
+
+	return nil
+}
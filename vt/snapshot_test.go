@@ -0,0 +1,67 @@
+package vt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScreen_Snapshot(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		output string
+	}{
+		{name: "text and attributes", output: "\x1b[1;31mred\x1b[0m plain\r\n\x1b[38;5;100;48;2;1;2;3mext\x1b[44m\x1b[K\x1b[0m\r\n界 wide"},
+		{name: "cursor and pen", output: "abc\x1b[2;5H\x1b[4;93m\x1b(0"},
+		{name: "pending wrap", output: "\x1b[3;1H0123456789"},
+		{name: "wrapped lines", output: "0123456789abc\r\n012345678界"},
+		{name: "wrapped blank", output: "0123456789 \r\n"},
+		{name: "alternate screen", output: "main\x1b[2;3H\x1b[?1049h\x1b[Halt\x1b]0;title\x07"},
+		{name: "modes and region", output: "text\x1b[2;3r\x1b[?6h\x1b[?25l\x1b[?2004h\x1b[4h\x1b[2;2H\x1b[?7l"},
+		{name: "saved cursor", output: "\x1b[3;4H\x1b[7m\x1b7\x1b[H\x1b[0mx"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(10, 3)
+			s.Write([]byte(tc.output))
+
+			restored := New(10, 3)
+			restored.Write([]byte("garbage\r\n\x1b[1mto be reset"))
+			restored.Write(s.Snapshot())
+
+			if s.Text() != restored.Text() || s.Title() != restored.Title() || s.AltScreen() != restored.AltScreen() {
+				t.Fatalf("Unexpected screen %q, expected %q", restored.Text(), s.Text())
+			}
+
+			for y := 0; y < s.Height(); y++ {
+				if s.Line(y).Wrapped != restored.Line(y).Wrapped {
+					t.Fatalf("Wrap of line %d differs", y)
+				}
+
+				for x := 0; x < s.Width(); x++ {
+					if s.Cell(x, y) != restored.Cell(x, y) {
+						t.Fatalf("Cell %d,%d differs: %+v, expected %+v", x, y, restored.Cell(x, y), s.Cell(x, y))
+					}
+				}
+			}
+
+			if s.cursor != restored.cursor || s.buf.saved != restored.buf.saved {
+				t.Fatalf("Unexpected cursor %+v, expected %+v", restored.cursor, s.cursor)
+			}
+
+			if s.top != restored.top || s.bottom != restored.bottom || s.autowrap != restored.autowrap ||
+				s.insert != restored.insert || s.cursorVisible != restored.cursorVisible ||
+				!reflect.DeepEqual(s.privateModes, restored.privateModes) {
+				t.Fatalf("Modes differ")
+			}
+
+			if s.AltScreen() {
+				// leaving alternate screen gives the same main screen
+				s.Write([]byte("\x1b[?1049l"))
+				restored.Write([]byte("\x1b[?1049l"))
+
+				if s.Text() != restored.Text() || s.cursor != restored.cursor {
+					t.Fatalf("Unexpected main screen %q, expected %q", restored.Text(), s.Text())
+				}
+			}
+		})
+	}
+}
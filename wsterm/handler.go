@@ -0,0 +1,115 @@
+package wsterm
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+
+	"nhooyr.io/websocket"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// Recording is a recording requested by client.
+type Recording struct {
+	Source player.FrameSource
+
+	// Close is called after playback. May be nil.
+	Close func() error
+
+	// Options for Player.
+	Options []player.Option
+
+	// Metadata is sent to client before playback if not nil.
+	Metadata *Metadata
+}
+
+// Handler plays recordings to websocket clients. Playback runs within ServeHTTP, so it's bound to request context.
+type Handler struct {
+	// Open opens recording requested by client. Error wrapping fs.ErrNotExist results in 404 response,
+	// other errors result in 400 response.
+	Open func(r *http.Request) (*Recording, error)
+
+	// AcceptOptions for websocket connection. Subprotocols are set by Handler.
+	AcceptOptions websocket.AcceptOptions
+
+	// Options for Term.
+	Options []Option
+
+	// ErrorLog logs failed playbacks. Standard logger is used if nil.
+	ErrorLog *log.Logger
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec, err := h.Open(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, fs.ErrNotExist) {
+			status = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	if rec.Close != nil {
+		defer rec.Close()
+	}
+
+	acceptOptions := h.AcceptOptions
+	acceptOptions.Subprotocols = []string{Subprotocol}
+
+	conn, err := websocket.Accept(w, r, &acceptOptions)
+	if err != nil {
+		h.logf("websocket accept failed: %s", err)
+		return
+	}
+
+	term, err := New(r.Context(), conn, h.Options...)
+	if err != nil {
+		conn.Close(websocket.StatusProtocolError, closeReason(err.Error()))
+		h.logf("terminal init failed: %s", err)
+
+		return
+	}
+
+	if rec.Metadata != nil {
+		if err = term.SendMetadata(*rec.Metadata); err != nil {
+			term.CloseWithError(err)
+			h.logf("metadata send failed: %s", err)
+
+			return
+		}
+	}
+
+	p, err := player.NewPlayer(rec.Source, term, rec.Options...)
+	if err != nil {
+		term.CloseWithError(err)
+		h.logf("player create failed: %s", err)
+
+		return
+	}
+
+	if err = p.Start(); err != nil {
+		term.CloseWithError(err)
+		h.logf("playback failed: %s", err)
+
+		return
+	}
+
+	if err = term.Err(); err != nil {
+		h.logf("connection failed: %s", err)
+	}
+
+	term.Close()
+}
+
+func (h *Handler) logf(format string, args ...interface{}) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
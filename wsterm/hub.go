@@ -0,0 +1,358 @@
+package wsterm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"nhooyr.io/websocket"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// DefaultViewerQueueSize is a default size of output in bytes queued for broadcast viewer.
+const DefaultViewerQueueSize = 1 << 20
+
+// maxViewerMessages is a maximal number of messages queued for viewer, it limits queue of messages without output.
+const maxViewerMessages = 4096
+
+var ErrHubClosed = fmt.Errorf("hub closed")
+
+type hubOptions struct {
+	viewerQueueSize int
+	acceptOptions   websocket.AcceptOptions
+	termOptions     []Option
+	errorLog        *log.Logger
+}
+
+// HubOption is an option for Hub.
+type HubOption func(*hubOptions)
+
+// WithViewerQueueSize sets size of output in bytes queued for every viewer. Viewer with full queue is disconnected.
+// Zero or negative values are ignored.
+func WithViewerQueueSize(size int) HubOption {
+	return func(o *hubOptions) {
+		if size > 0 {
+			o.viewerQueueSize = size
+		}
+	}
+}
+
+// WithAcceptOptions sets options for viewer websocket connections. Subprotocols are set by Hub.
+func WithAcceptOptions(acceptOptions websocket.AcceptOptions) HubOption {
+	return func(o *hubOptions) {
+		o.acceptOptions = acceptOptions
+	}
+}
+
+// WithTermOptions sets options for viewer terminals.
+func WithTermOptions(opts ...Option) HubOption {
+	return func(o *hubOptions) {
+		o.termOptions = opts
+	}
+}
+
+// WithErrorLog sets logger for failed viewer connections. Standard logger is used by default.
+func WithErrorLog(l *log.Logger) HubOption {
+	return func(o *hubOptions) {
+		o.errorLog = l
+	}
+}
+
+// Hub broadcasts live session to many websocket viewers. It's a player.FrameWriter, so recorder may write frames
+// to it directly, frames of FrameSource may be broadcast with Broadcast.
+//
+// Screen of session is tracked by virtual terminal: joining viewer gets snapshot of current screen first
+// and live output after that. Output is queued for every viewer, viewer that doesn't keep up with session
+// is disconnected instead of slowing down session and other viewers.
+type Hub struct {
+	header   player.Header
+	metadata *Metadata
+	options  hubOptions
+
+	mu      sync.Mutex
+	screen  *vt.Screen
+	viewers map[*viewer]struct{}
+	closed  bool
+}
+
+type viewer struct {
+	queued int64 // size of queued output, first for atomic access alignment
+	queue  chan Message
+
+	// done is closed when viewer is removed from hub, status tells why.
+	done   chan struct{}
+	status websocket.StatusCode
+	reason string
+}
+
+// NewHub creates Hub for session described by header.
+func NewHub(header player.Header, opts ...HubOption) *Hub {
+	o := hubOptions{viewerQueueSize: DefaultViewerQueueSize}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	h := &Hub{
+		header:  header,
+		options: o,
+		screen:  vt.New(header.Width, header.Height),
+		viewers: make(map[*viewer]struct{}),
+	}
+
+	if header.Title != "" {
+		h.metadata = &Metadata{Title: header.Title}
+	}
+
+	return h
+}
+
+// WriteFrame broadcasts frame to viewers. Output and resize frames are broadcast, other frames are ignored.
+// It never blocks on viewers.
+func (h *Hub) WriteFrame(frame player.Frame) error {
+	var msg Message
+
+	switch frame.Type {
+	case player.OutputFrame:
+		msg = Message{Type: DataMessage, Data: string(frame.Data)}
+	case player.ResizeFrame:
+		width, height, err := player.ParseResizeData(frame.Data)
+		if err != nil {
+			return err
+		}
+
+		msg = Message{Type: ResizeMessage, Dimensions: &Dimensions{Width: width, Height: height}}
+	default:
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return ErrHubClosed
+	}
+
+	if msg.Type == ResizeMessage {
+		h.screen.Resize(msg.Dimensions.Width, msg.Dimensions.Height)
+	} else {
+		h.screen.Write(frame.Data)
+	}
+
+	h.broadcastLocked(msg)
+
+	return nil
+}
+
+// broadcast sends message to viewers without changing screen.
+func (h *Hub) broadcast(msg Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.closed {
+		h.broadcastLocked(msg)
+	}
+}
+
+func (h *Hub) broadcastLocked(msg Message) {
+	size := int64(len(msg.Data))
+
+	for v := range h.viewers {
+		if atomic.LoadInt64(&v.queued)+size > int64(h.options.viewerQueueSize) {
+			h.removeLocked(v, websocket.StatusPolicyViolation, "viewer is too slow")
+			continue
+		}
+
+		select {
+		case v.queue <- msg:
+			atomic.AddInt64(&v.queued, size)
+		default:
+			h.removeLocked(v, websocket.StatusPolicyViolation, "viewer is too slow")
+		}
+	}
+}
+
+// Broadcast writes frames of source to hub as soon as source returns them, so source should be live
+// (i.e. followed cast). When context is done, source is closed if it's an io.Closer and Broadcast returns.
+func (h *Hub) Broadcast(ctx context.Context, src player.FrameSource) error {
+	if closer, ok := src.(io.Closer); ok {
+		stop := make(chan struct{})
+		defer close(stop)
+
+		go func() {
+			select {
+			case <-ctx.Done():
+				closer.Close()
+			case <-stop:
+			}
+		}()
+	}
+
+	for src.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := h.WriteFrame(src.Frame()); err != nil {
+			return err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return src.Err()
+}
+
+// Viewers returns number of connected viewers.
+func (h *Hub) Viewers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.viewers)
+}
+
+// Close disconnects all viewers with normal closure after sending queued output. Frames written after Close are rejected.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for v := range h.viewers {
+		h.removeLocked(v, websocket.StatusNormalClosure, "broadcast finished")
+	}
+
+	return nil
+}
+
+// ServeHTTP connects viewer. Messages from viewer are ignored.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, nil)
+}
+
+// serve connects viewer, messages from viewer are passed to handle if it's not nil.
+func (h *Hub) serve(w http.ResponseWriter, r *http.Request, handle func(Message)) {
+	acceptOptions := h.options.acceptOptions
+	acceptOptions.Subprotocols = []string{Subprotocol}
+
+	conn, err := websocket.Accept(w, r, &acceptOptions)
+	if err != nil {
+		h.logf("websocket accept failed: %s", err)
+		return
+	}
+
+	term, err := New(r.Context(), conn, h.options.termOptions...)
+	if err != nil {
+		conn.Close(websocket.StatusProtocolError, closeReason(err.Error()))
+		h.logf("terminal init failed: %s", err)
+
+		return
+	}
+
+	if h.metadata != nil {
+		if err = term.SendMetadata(*h.metadata); err != nil {
+			term.CloseWithError(err)
+			h.logf("metadata send failed: %s", err)
+
+			return
+		}
+	}
+
+	v, ok := h.join()
+	if !ok {
+		term.Close()
+		return
+	}
+
+	defer h.remove(v, websocket.StatusGoingAway, "")
+
+	go term.readMessages(handle)
+
+	for {
+		select {
+		case msg := <-v.queue:
+			atomic.AddInt64(&v.queued, -int64(len(msg.Data)))
+
+			if err = term.send(msg); err != nil {
+				if term.ctx.Err() == nil {
+					term.CloseWithError(err)
+					h.logf("viewer send failed: %s", err)
+				}
+
+				return
+			}
+		case <-v.done:
+			if v.status == websocket.StatusNormalClosure {
+				if !term.flush(v.queue) {
+					return
+				}
+			}
+
+			term.close(v.status, v.reason)
+
+			return
+		case <-term.ctx.Done():
+			return
+		}
+	}
+}
+
+// join registers viewer with current screen snapshot queued.
+func (h *Hub) join() (*viewer, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, false
+	}
+
+	// snapshot messages don't count toward message limit, snapshot is a part of queued output
+	v := &viewer{
+		queue: make(chan Message, maxViewerMessages+2),
+		done:  make(chan struct{}),
+	}
+
+	snapshot := string(h.screen.Snapshot())
+	v.queued = int64(len(snapshot))
+
+	v.queue <- Message{Type: ResizeMessage, Dimensions: &Dimensions{Width: h.screen.Width(), Height: h.screen.Height()}}
+	v.queue <- Message{Type: DataMessage, Data: snapshot}
+
+	h.viewers[v] = struct{}{}
+
+	return v, true
+}
+
+func (h *Hub) remove(v *viewer, status websocket.StatusCode, reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(v, status, reason)
+}
+
+func (h *Hub) removeLocked(v *viewer, status websocket.StatusCode, reason string) {
+	if _, ok := h.viewers[v]; !ok {
+		return
+	}
+
+	delete(h.viewers, v)
+
+	v.status, v.reason = status, reason
+	close(v.done)
+}
+
+func (h *Hub) logf(format string, args ...interface{}) {
+	if h.options.errorLog != nil {
+		h.options.errorLog.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
@@ -0,0 +1,188 @@
+package wsterm
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+func newTestHub(t *testing.T, opts ...HubOption) (*Hub, string) {
+	t.Helper()
+
+	hub := NewHub(player.Header{Version: 2, Width: 20, Height: 5, Title: "live"}, opts...)
+
+	srv := httptest.NewServer(hub)
+	t.Cleanup(srv.Close)
+
+	return hub, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func waitViewers(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); hub.Viewers() != n; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d viewers, got %d", n, hub.Viewers())
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub, url := newTestHub(t)
+
+	frames := []player.Frame{
+		{Time: 0.1, Type: player.OutputFrame, Data: []byte("\x1b[1mhello\x1b[0m\r\n")},
+		{Time: 0.2, Type: player.InputFrame, Data: []byte("x")},
+		{Time: 0.3, Type: player.ResizeFrame, Data: []byte("30x6")},
+		{Time: 0.4, Type: player.OutputFrame, Data: []byte("wor")},
+	}
+
+	for _, frame := range frames {
+		if err := hub.WriteFrame(frame); err != nil {
+			t.Fatalf("Write failed: %s", err)
+		}
+	}
+
+	conn := dial(t, url, Dimensions{Width: 80, Height: 24})
+	waitViewers(t, hub, 1)
+
+	err := hub.WriteFrame(player.Frame{Time: 0.5, Type: player.OutputFrame, Data: []byte("ld")})
+	if err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+
+	hub.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		messages []Message
+		screen   *vt.Screen
+	)
+
+	for {
+		var msg Message
+		if err = wsjson.Read(ctx, conn, &msg); err != nil {
+			break
+		}
+
+		messages = append(messages, msg)
+
+		switch msg.Type {
+		case ResizeMessage:
+			screen = vt.New(msg.Dimensions.Width, msg.Dimensions.Height)
+		case DataMessage:
+			screen.Write([]byte(msg.Data))
+		}
+	}
+
+	if status := websocket.CloseStatus(err); status != websocket.StatusNormalClosure {
+		t.Fatalf("Unexpected close: %s", err)
+	}
+
+	if len(messages) != 4 || messages[0].Type != MetadataMessage || messages[0].Metadata.Title != "live" ||
+		messages[1].Type != ResizeMessage || messages[3].Data != "ld" {
+		t.Fatalf("Unexpected messages: %+v", messages)
+	}
+
+	if screen.Width() != 30 || screen.Line(0).String() != "hello" || screen.Line(1).String() != "world" ||
+		screen.Cell(0, 0).Attr.Flags&vt.Bold == 0 {
+		t.Fatalf("Unexpected screen:\n%s", screen.Text())
+	}
+
+	if err = hub.WriteFrame(player.Frame{Type: player.OutputFrame, Data: []byte("!")}); err != ErrHubClosed {
+		t.Fatalf("Write after close must fail, got %v", err)
+	}
+}
+
+func TestHub_SlowViewer(t *testing.T) {
+	hub := NewHub(player.Header{Version: 2, Width: 20, Height: 5})
+
+	// viewer that doesn't read its queue
+	v := &viewer{queue: make(chan Message, 1), done: make(chan struct{})}
+	hub.viewers[v] = struct{}{}
+
+	for _, data := range []string{"a", "b"} {
+		if err := hub.WriteFrame(player.Frame{Type: player.OutputFrame, Data: []byte(data)}); err != nil {
+			t.Fatalf("Write failed: %s", err)
+		}
+	}
+
+	select {
+	case <-v.done:
+	default:
+		t.Fatalf("Slow viewer was not disconnected")
+	}
+
+	if v.status != websocket.StatusPolicyViolation || hub.Viewers() != 0 {
+		t.Fatalf("Unexpected status %s, viewers %d", v.status, hub.Viewers())
+	}
+}
+
+func TestHub_SlowViewerSize(t *testing.T) {
+	hub := NewHub(player.Header{Version: 2, Width: 20, Height: 5}, WithViewerQueueSize(10))
+
+	// queue has room for messages but not for their output
+	v := &viewer{queue: make(chan Message, 10), done: make(chan struct{})}
+	hub.viewers[v] = struct{}{}
+
+	for _, data := range []string{"hello", "world"} {
+		if err := hub.WriteFrame(player.Frame{Type: player.OutputFrame, Data: []byte(data)}); err != nil {
+			t.Fatalf("Write failed: %s", err)
+		}
+	}
+
+	if hub.Viewers() != 1 {
+		t.Fatalf("Viewer with full queue was disconnected early")
+	}
+
+	if err := hub.WriteFrame(player.Frame{Type: player.OutputFrame, Data: []byte("!")}); err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+
+	select {
+	case <-v.done:
+	default:
+		t.Fatalf("Slow viewer was not disconnected")
+	}
+
+	if v.status != websocket.StatusPolicyViolation || hub.Viewers() != 0 {
+		t.Fatalf("Unexpected status %s, viewers %d", v.status, hub.Viewers())
+	}
+}
+
+func TestHub_Broadcast(t *testing.T) {
+	hub, _ := newTestHub(t)
+
+	src, err := player.NewStreamFrameSource(strings.NewReader(testCast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	if err = hub.Broadcast(context.Background(), src); err != nil {
+		t.Fatalf("Broadcast failed: %s", err)
+	}
+
+	if text := hub.screen.Text(); !strings.HasPrefix(text, "hello world!") {
+		t.Fatalf("Unexpected screen: %q", text)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	src, _ = player.NewStreamFrameSource(strings.NewReader(testCast))
+	if err = hub.Broadcast(ctx, src); err != context.Canceled {
+		t.Fatalf("Canceled broadcast must fail, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package wsterm
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+// Role of Party member.
+type Role int
+
+const (
+	// Viewer only watches playback, its control messages are ignored.
+	Viewer Role = iota
+
+	// Controller may pause, seek, change speed and stop playback for all members.
+	Controller
+)
+
+// Party plays recording to many clients at once ("watch party"). It's a player.Terminal driven by single Player,
+// output is broadcast by Hub: joining member gets screen at current position and then output of shared playback.
+// Playback starts paused if there may be controllers, control messages of controllers apply to all members.
+// Playback is stopped when last member leaves.
+type Party struct {
+	hub    *Hub
+	rec    Recording
+	role   func(r *http.Request) (Role, error)
+	player *player.Player
+
+	positionInterval time.Duration
+
+	mu      sync.Mutex
+	control player.PlaybackControl
+	paused  bool
+	members int // counted before handshake, so party isn't stopped while member connects
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewParty creates Party for recording. Role returns role of connecting client, error results in 403 response.
+// All clients are viewers and playback starts immediately if role is nil.
+func NewParty(rec Recording, role func(r *http.Request) (Role, error), opts ...HubOption) (*Party, error) {
+	hdr := rec.Source.Header()
+
+	p := &Party{
+		hub:    NewHub(hdr, opts...),
+		rec:    rec,
+		role:   role,
+		closed: make(chan struct{}),
+	}
+
+	if rec.Metadata != nil {
+		p.hub.metadata = rec.Metadata
+	}
+
+	// position updates are configured by the same option as for playback to single client
+	p.positionInterval = newOptions(p.hub.options.termOptions...).positionInterval
+
+	var err error
+
+	p.player, err = player.NewPlayer(rec.Source, p, rec.Options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Play runs shared playback. Method blocks until end of recording or Stop call, then members are disconnected
+// and recording is closed.
+func (p *Party) Play() error {
+	err := p.player.Start()
+
+	p.hub.Close()
+	p.Close()
+
+	if p.rec.Close != nil {
+		if closeErr := p.rec.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// Stop interrupts playback.
+func (p *Party) Stop() {
+	p.player.Stop()
+}
+
+// Members returns number of connected and connecting members.
+func (p *Party) Members() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.members
+}
+
+// ServeHTTP connects member with role returned by role function.
+func (p *Party) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	role := Viewer
+
+	if p.role != nil {
+		var err error
+		if role, err = p.role(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	var handle func(Message)
+	if role == Controller {
+		handle = p.handle
+	}
+
+	p.mu.Lock()
+	p.members++
+	p.mu.Unlock()
+
+	p.hub.serve(w, r, handle)
+
+	p.mu.Lock()
+	p.members--
+	last := p.members == 0
+	p.mu.Unlock()
+
+	if last {
+		p.Stop()
+	}
+}
+
+func (p *Party) handle(msg Message) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.control == nil {
+		return
+	}
+
+	switch msg.Type {
+	case PlayPauseMessage:
+		p.control.Pause()
+		p.paused = !p.paused
+	case StopMessage:
+		p.control.Stop()
+	case SeekMessage:
+		p.control.Seek(msg.Position)
+	case SpeedMessage:
+		p.control.SetSpeed(msg.Speed)
+	default:
+		return
+	}
+
+	// members see result of control immediately
+	p.sendPositionLocked()
+}
+
+// Write broadcasts output to members.
+func (p *Party) Write(data []byte) (int, error) {
+	if err := p.hub.WriteFrame(player.Frame{Type: player.OutputFrame, Data: data}); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
+
+// Dimensions returns size of recording, members terminals are resized to it.
+func (p *Party) Dimensions() (width, height int) {
+	hdr := p.rec.Source.Header()
+
+	return hdr.Width, hdr.Height
+}
+
+// ToRaw does nothing.
+func (p *Party) ToRaw() error { return nil }
+
+// Restore does nothing.
+func (p *Party) Restore() error { return nil }
+
+// Control pauses playback until controller starts it if there may be controllers and sends position updates to members.
+func (p *Party) Control(control player.PlaybackControl) {
+	p.mu.Lock()
+	p.control = control
+
+	if p.role != nil {
+		p.control.Pause()
+		p.paused = true
+	}
+
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(p.positionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			p.sendPositionLocked()
+			p.mu.Unlock()
+		case <-p.closed:
+			return
+		}
+	}
+}
+
+func (p *Party) sendPositionLocked() {
+	p.hub.broadcast(Message{Type: PositionMessage, Position: p.control.Position(), Paused: p.paused})
+}
+
+// Close stops position updates. Members are disconnected by Play.
+func (p *Party) Close() error {
+	p.closeOnce.Do(func() { close(p.closed) })
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package wsterm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	player "github.com/xakep666/asciinema-player/v3"
+	"github.com/xakep666/asciinema-player/v3/vt"
+)
+
+// readScreen plays messages to virtual terminal until stop returns true or connection is closed.
+func readScreen(t *testing.T, conn *websocket.Conn, stop func(screen *vt.Screen, msg Message) bool) (*vt.Screen, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var screen *vt.Screen
+
+	for {
+		var msg Message
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			return screen, err
+		}
+
+		switch msg.Type {
+		case ResizeMessage:
+			screen = vt.New(msg.Dimensions.Width, msg.Dimensions.Height)
+		case DataMessage:
+			screen.Write([]byte(msg.Data))
+		}
+
+		if screen != nil && stop(screen, msg) {
+			return screen, nil
+		}
+	}
+}
+
+func TestParty(t *testing.T) {
+	src, err := player.NewStreamFrameSource(strings.NewReader(testCast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	party, err := NewParty(Recording{Source: src, Metadata: &Metadata{Duration: 60}}, func(r *http.Request) (Role, error) {
+		switch key := r.URL.Query().Get("key"); key {
+		case "":
+			return Viewer, nil
+		case "secret":
+			return Controller, nil
+		default:
+			return Viewer, fmt.Errorf("invalid key %q", key)
+		}
+	})
+	if err != nil {
+		t.Fatalf("NewParty failed: %s", err)
+	}
+
+	played := make(chan error, 1)
+	go func() { played <- party.Play() }()
+
+	srv := httptest.NewServer(party)
+	t.Cleanup(srv.Close)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	resp, err := http.Get(srv.URL + "?key=wrong")
+	if err != nil {
+		t.Fatalf("Request failed: %s", err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Unexpected status %d", resp.StatusCode)
+	}
+
+	viewer := dial(t, url, Dimensions{Width: 80, Height: 24})
+	controller := dial(t, url+"?key=secret", Dimensions{Width: 80, Height: 24})
+	waitViewers(t, party.hub, 2)
+
+	// control messages of viewer are ignored, playback starts paused
+	wsjson.Write(context.Background(), viewer, Message{Type: SeekMessage, Position: 60})
+	wsjson.Write(context.Background(), controller, Message{Type: PlayPauseMessage})
+
+	started := false
+
+	screen, err := readScreen(t, viewer, func(screen *vt.Screen, msg Message) bool {
+		if msg.Type == PositionMessage && !msg.Paused {
+			started = true
+		}
+
+		return screen.Line(0).String() == "hello world"
+	})
+	if err != nil || !started {
+		t.Fatalf("Playback was not started: %v, screen:\n%s", err, screen.Text())
+	}
+
+	late := dial(t, url, Dimensions{Width: 80, Height: 24})
+	waitViewers(t, party.hub, 3)
+
+	wsjson.Write(context.Background(), controller, Message{Type: SeekMessage, Position: 60})
+
+	screen, err = readScreen(t, late, func(*vt.Screen, Message) bool { return false })
+	if status := websocket.CloseStatus(err); status != websocket.StatusNormalClosure {
+		t.Fatalf("Unexpected close: %s", err)
+	}
+
+	if screen.Line(0).String() != "hello world!" {
+		t.Fatalf("Unexpected screen:\n%s", screen.Text())
+	}
+
+	if err = <-played; err != nil {
+		t.Fatalf("Play failed: %s", err)
+	}
+}
+
+func TestParty_NoControllers(t *testing.T) {
+	src, err := player.NewStreamFrameSource(strings.NewReader(testCast))
+	if err != nil {
+		t.Fatalf("Source create failed: %s", err)
+	}
+
+	party, err := NewParty(Recording{Source: src}, nil)
+	if err != nil {
+		t.Fatalf("NewParty failed: %s", err)
+	}
+
+	played := make(chan error, 1)
+	go func() { played <- party.Play() }()
+
+	srv := httptest.NewServer(party)
+	t.Cleanup(srv.Close)
+
+	// playback without controllers isn't paused
+	viewer := dial(t, "ws"+strings.TrimPrefix(srv.URL, "http"), Dimensions{Width: 80, Height: 24})
+
+	screen, err := readScreen(t, viewer, func(screen *vt.Screen, msg Message) bool {
+		return screen.Line(0).String() == "hello world"
+	})
+	if err != nil {
+		t.Fatalf("Playback was not started: %v, screen:\n%s", err, screen.Text())
+	}
+
+	// playback is stopped when last member leaves
+	viewer.Close(websocket.StatusNormalClosure, "")
+
+	select {
+	case err = <-played:
+		if err != nil {
+			t.Fatalf("Play failed: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Playback was not stopped")
+	}
+}
@@ -0,0 +1,99 @@
+// Package wsterm provides player.Terminal working over websocket and http.Handler playing recordings to browsers.
+//
+// Client opens websocket connection requesting Subprotocol. Connections without subprotocol are treated
+// as protocol version 1 for compatibility with early clients. All messages are JSON text messages of Message format.
+//
+// First message must be sent by client: DimensionsMessage with size of its terminal. Then server sends
+// MetadataMessage (if recording metadata is known), DataMessage with terminal output and periodic PositionMessage.
+// Client may send PlayPauseMessage, StopMessage, SeekMessage and SpeedMessage to control playback.
+// Messages of unknown types are ignored, so new message types may be added within protocol version.
+// When playback ends, server closes connection with normal closure status. Errors are reported
+// by closing connection with error status and reason.
+//
+// Live session viewers (see Hub) get ResizeMessage with session terminal size, DataMessage with current screen
+// and live output after that. Control messages are ignored for them. Members of shared playback (see Party) get
+// the same messages, but PositionMessage and control messages from controllers are supported.
+package wsterm
+
+// ProtocolVersion is a version of protocol implemented by package.
+const ProtocolVersion = 1
+
+// Subprotocol is a websocket subprotocol name of current protocol version.
+const Subprotocol = "asciinema-player.v1"
+
+// MessageType is a type of protocol message.
+type MessageType int
+
+const (
+	// DimensionsMessage is sent by client first, it contains size of client terminal.
+	DimensionsMessage MessageType = iota
+
+	// DataMessage is sent by server, it contains terminal output.
+	DataMessage
+
+	// PlayPauseMessage is sent by client to pause or continue playback.
+	PlayPauseMessage
+
+	// StopMessage is sent by client to stop playback.
+	StopMessage
+
+	// MetadataMessage is sent by server before output, it contains duration, markers and title of recording.
+	MetadataMessage
+
+	// PositionMessage is sent by server periodically, it contains playback position.
+	PositionMessage
+
+	// SeekMessage is sent by client to move playback to position.
+	SeekMessage
+
+	// SpeedMessage is sent by client to change playback speed.
+	SpeedMessage
+
+	// ResizeMessage is sent by server of live session first and when session terminal size changes,
+	// it contains new size.
+	ResizeMessage
+)
+
+// Dimensions is a terminal size in characters.
+type Dimensions struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Marker is a marker (chapter) of recording.
+type Marker struct {
+	Time  float64 `json:"time"`
+	Label string  `json:"label"`
+}
+
+// Metadata describes recording.
+type Metadata struct {
+	// Duration of recording in seconds.
+	Duration float64 `json:"duration"`
+
+	Markers []Marker `json:"markers,omitempty"`
+	Title   string   `json:"title,omitempty"`
+}
+
+// Message is a protocol message.
+type Message struct {
+	Type MessageType `json:"type"`
+
+	// Dimensions is set for DimensionsMessage and ResizeMessage.
+	Dimensions *Dimensions `json:"dimensions,omitempty"`
+
+	// Data is set for DataMessage.
+	Data string `json:"data,omitempty"`
+
+	// Metadata is set for MetadataMessage.
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Position in seconds is set for PositionMessage and SeekMessage. Absent value means zero.
+	Position float64 `json:"position,omitempty"`
+
+	// Speed is set for SpeedMessage.
+	Speed float64 `json:"speed,omitempty"`
+
+	// Paused is set for PositionMessage of shared playback (see Party) when it's paused.
+	Paused bool `json:"paused,omitempty"`
+}
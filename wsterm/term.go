@@ -0,0 +1,312 @@
+package wsterm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const (
+	// DefaultWriteTimeout is a default time limit for sending output message to client.
+	DefaultWriteTimeout = 10 * time.Second
+
+	// DefaultHandshakeTimeout is a default time limit for receiving dimensions from client.
+	DefaultHandshakeTimeout = 10 * time.Second
+
+	// DefaultPositionInterval is a default interval of position updates.
+	DefaultPositionInterval = 500 * time.Millisecond
+
+	// maxClientMessage is a read limit for client messages, they are small control messages.
+	maxClientMessage = 4096
+
+	// maxCloseReason is a maximal length of close reason allowed by websocket protocol.
+	maxCloseReason = 123
+)
+
+var (
+	ErrUnsupportedProtocol = fmt.Errorf("unsupported protocol")
+	ErrHandshake           = fmt.Errorf("handshake failed")
+)
+
+type options struct {
+	writeTimeout     time.Duration
+	handshakeTimeout time.Duration
+	positionInterval time.Duration
+}
+
+// Option for Term.
+type Option func(*options)
+
+func newOptions(opts ...Option) options {
+	o := options{
+		writeTimeout:     DefaultWriteTimeout,
+		handshakeTimeout: DefaultHandshakeTimeout,
+		positionInterval: DefaultPositionInterval,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithWriteTimeout sets time limit for sending output to client. Zero or negative values are ignored.
+func WithWriteTimeout(t time.Duration) Option {
+	return func(o *options) {
+		if t > 0 {
+			o.writeTimeout = t
+		}
+	}
+}
+
+// WithHandshakeTimeout sets time limit for receiving dimensions message. Zero or negative values are ignored.
+func WithHandshakeTimeout(t time.Duration) Option {
+	return func(o *options) {
+		if t > 0 {
+			o.handshakeTimeout = t
+		}
+	}
+}
+
+// WithPositionInterval sets interval of position updates sent to client. Zero or negative values are ignored.
+func WithPositionInterval(t time.Duration) Option {
+	return func(o *options) {
+		if t > 0 {
+			o.positionInterval = t
+		}
+	}
+}
+
+// Term is a player.Terminal sending output to websocket client.
+//
+// Write blocks until message is sent, so slow client slows down playback instead of unlimited buffering
+// of output on server side. Write fails if message was not sent within write timeout.
+type Term struct {
+	conn    *websocket.Conn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	options options
+
+	dimensions Dimensions
+
+	closeOnce sync.Once
+	closeErr  error
+
+	mu  sync.Mutex
+	err error
+}
+
+// New performs handshake: it checks negotiated subprotocol and waits for dimensions message from client.
+// Context bounds lifetime of Term: when it's done, writes fail and Control returns.
+func New(ctx context.Context, conn *websocket.Conn, opts ...Option) (*Term, error) {
+	o := newOptions(opts...)
+
+	if proto := conn.Subprotocol(); proto != "" && proto != Subprotocol {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedProtocol, proto)
+	}
+
+	conn.SetReadLimit(maxClientMessage)
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, o.handshakeTimeout)
+	defer cancel()
+
+	var msg Message
+	if err := wsjson.Read(handshakeCtx, conn, &msg); err != nil {
+		return nil, fmt.Errorf("%w: dimensions read error: %s", ErrHandshake, err)
+	}
+
+	if msg.Type != DimensionsMessage || msg.Dimensions == nil {
+		return nil, fmt.Errorf("%w: first message was not about dimensions", ErrHandshake)
+	}
+
+	t := &Term{
+		conn:       conn,
+		options:    o,
+		dimensions: *msg.Dimensions,
+	}
+
+	t.ctx, t.cancel = context.WithCancel(ctx)
+
+	return t, nil
+}
+
+// Write sends output to client.
+func (t *Term) Write(p []byte) (n int, err error) {
+	if err = t.send(Message{Type: DataMessage, Data: string(p)}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// SendMetadata sends recording metadata to client. It should be called before playback start.
+func (t *Term) SendMetadata(metadata Metadata) error {
+	return t.send(Message{Type: MetadataMessage, Metadata: &metadata})
+}
+
+func (t *Term) send(msg Message) error {
+	ctx, cancel := context.WithTimeout(t.ctx, t.options.writeTimeout)
+	defer cancel()
+
+	return wsjson.Write(ctx, t.conn, msg)
+}
+
+// Close closes connection with normal closure status.
+func (t *Term) Close() error {
+	return t.close(websocket.StatusNormalClosure, "goodbye")
+}
+
+// CloseWithError closes connection with internal error status and error text as a reason.
+func (t *Term) CloseWithError(err error) error {
+	return t.close(websocket.StatusInternalError, err.Error())
+}
+
+func (t *Term) close(code websocket.StatusCode, reason string) error {
+	t.closeOnce.Do(func() {
+		// cancel after close handshake: reads with canceled context drop connection
+		t.closeErr = t.conn.Close(code, closeReason(reason))
+		t.cancel()
+	})
+
+	return t.closeErr
+}
+
+// closeReason truncates reason to length allowed by websocket protocol.
+func closeReason(reason string) string {
+	if len(reason) > maxCloseReason {
+		return strings.ToValidUTF8(reason[:maxCloseReason], "")
+	}
+
+	return reason
+}
+
+// Dimensions returns size of client terminal.
+func (t *Term) Dimensions() (width, height int) {
+	return t.dimensions.Width, t.dimensions.Height
+}
+
+// ToRaw does nothing.
+func (t *Term) ToRaw() error { return nil }
+
+// Restore does nothing.
+func (t *Term) Restore() error { return nil }
+
+// Control reads control messages from client and sends position updates. Playback is stopped if connection fails
+// or client goes away, error is available from Err after that.
+func (t *Term) Control(control player.PlaybackControl) {
+	ctx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+
+	go t.sendPositions(ctx, control)
+
+	for {
+		var msg Message
+
+		if err := wsjson.Read(t.ctx, t.conn, &msg); err != nil {
+			if t.ctx.Err() == nil && !isNormalClose(err) {
+				t.setErr(fmt.Errorf("read error: %w", err))
+			}
+
+			control.Stop()
+
+			return
+		}
+
+		switch msg.Type {
+		case PlayPauseMessage:
+			control.Pause()
+		case StopMessage:
+			control.Stop()
+		case SeekMessage:
+			control.Seek(msg.Position)
+		case SpeedMessage:
+			control.SetSpeed(msg.Speed)
+		}
+	}
+}
+
+func (t *Term) sendPositions(ctx context.Context, control player.PlaybackControl) {
+	ticker := time.NewTicker(t.options.positionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// failed write breaks connection, so error will be reported by Control
+			if t.send(Message{Type: PositionMessage, Position: control.Position()}) != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readMessages passes client messages to handle until connection is closed. Messages are dropped if handle is nil,
+// reading is needed anyway to handle close handshake and pings.
+func (t *Term) readMessages(handle func(Message)) {
+	for {
+		var msg Message
+
+		if err := wsjson.Read(t.ctx, t.conn, &msg); err != nil {
+			if t.ctx.Err() == nil && !isNormalClose(err) {
+				t.setErr(fmt.Errorf("read error: %w", err))
+			}
+
+			t.cancel()
+
+			return
+		}
+
+		if handle != nil {
+			handle(msg)
+		}
+	}
+}
+
+// flush sends messages left in queue. It returns false if sending failed.
+func (t *Term) flush(queue chan Message) bool {
+	for {
+		select {
+		case msg := <-queue:
+			if err := t.send(msg); err != nil {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// Err returns error that interrupted Control.
+func (t *Term) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.err
+}
+
+func (t *Term) setErr(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.err = err
+}
+
+func isNormalClose(err error) bool {
+	switch websocket.CloseStatus(err) {
+	case websocket.StatusNormalClosure, websocket.StatusGoingAway:
+		return true
+	default:
+		return errors.Is(err, context.Canceled)
+	}
+}
@@ -0,0 +1,308 @@
+package wsterm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+
+	player "github.com/xakep666/asciinema-player/v3"
+)
+
+const testCast = `{"version":2,"width":20,"height":5}
+[0.01,"o","hello "]
+[0.02,"i","x"]
+[0.03,"o","world"]
+[60,"o","!"]
+`
+
+func newTestServer(t *testing.T, h *Handler) string {
+	t.Helper()
+
+	if h.Open == nil {
+		h.Open = func(r *http.Request) (*Recording, error) {
+			if r.URL.Query().Get("file") != "test.cast" {
+				return nil, fmt.Errorf("open %s: %w", r.URL.Query().Get("file"), fs.ErrNotExist)
+			}
+
+			src, err := player.NewStreamFrameSource(strings.NewReader(testCast))
+			if err != nil {
+				return nil, err
+			}
+
+			return &Recording{Source: src, Options: []player.Option{player.WithMaxWait(time.Second)}}, nil
+		}
+	}
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/?file=test.cast"
+}
+
+func dial(t *testing.T, url string, dimensions Dimensions) *websocket.Conn {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{Subprotocols: []string{Subprotocol}})
+	if err != nil {
+		t.Fatalf("Dial failed: %s", err)
+	}
+
+	t.Cleanup(func() { conn.Close(websocket.StatusNormalClosure, "") })
+
+	if conn.Subprotocol() != Subprotocol {
+		t.Fatalf("Unexpected subprotocol %q", conn.Subprotocol())
+	}
+
+	if err = wsjson.Write(ctx, conn, Message{Type: DimensionsMessage, Dimensions: &dimensions}); err != nil {
+		t.Fatalf("Dimensions write failed: %s", err)
+	}
+
+	return conn
+}
+
+// readOutput reads output messages until connection is closed. Position messages are skipped.
+func readOutput(t *testing.T, conn *websocket.Conn, fn func(data string)) error {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for {
+		var msg Message
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case DataMessage:
+			fn(msg.Data)
+		case PositionMessage:
+		default:
+			t.Fatalf("Unexpected message: %+v", msg)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	conn := dial(t, newTestServer(t, &Handler{}), Dimensions{Width: 80, Height: 24})
+
+	var output strings.Builder
+
+	err := readOutput(t, conn, func(data string) { output.WriteString(data) })
+	if status := websocket.CloseStatus(err); status != websocket.StatusNormalClosure {
+		t.Fatalf("Unexpected close: %s", err)
+	}
+
+	if output.String() != "hello world!" {
+		t.Fatalf("Unexpected output: %q", output.String())
+	}
+}
+
+func TestHandler_Control(t *testing.T) {
+	conn := dial(t, newTestServer(t, &Handler{}), Dimensions{Width: 80, Height: 24})
+
+	var output strings.Builder
+
+	started := time.Now()
+
+	err := readOutput(t, conn, func(data string) {
+		output.WriteString(data)
+
+		var msg Message
+		switch data {
+		case "hello ":
+			// pause and continue
+			msg.Type = PlayPauseMessage
+			wsjson.Write(context.Background(), conn, msg)
+			wsjson.Write(context.Background(), conn, msg)
+		case "world":
+			msg.Type = StopMessage
+			wsjson.Write(context.Background(), conn, msg)
+		}
+	})
+	if status := websocket.CloseStatus(err); status != websocket.StatusNormalClosure {
+		t.Fatalf("Unexpected close: %s", err)
+	}
+
+	if output.String() != "hello world" || time.Since(started) > 5*time.Second {
+		t.Fatalf("Playback was not stopped: %q", output.String())
+	}
+}
+
+func TestHandler_Seek(t *testing.T) {
+	h := &Handler{Options: []Option{WithPositionInterval(10 * time.Millisecond)}}
+	url := newTestServer(t, h)
+
+	open := h.Open
+	h.Open = func(r *http.Request) (*Recording, error) {
+		rec, err := open(r)
+		if err != nil {
+			return nil, err
+		}
+
+		rec.Options = nil // no pause limit
+		rec.Metadata = &Metadata{Duration: 60, Markers: []Marker{{Time: 0.03, Label: "world"}}, Title: "test"}
+
+		return rec, nil
+	}
+
+	conn := dial(t, url, Dimensions{Width: 80, Height: 24})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		messages []MessageType
+		output   strings.Builder
+		position float64
+	)
+
+	for {
+		var msg Message
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			if status := websocket.CloseStatus(err); status != websocket.StatusNormalClosure {
+				t.Fatalf("Unexpected close: %s", err)
+			}
+
+			break
+		}
+
+		if len(messages) == 0 || messages[len(messages)-1] != msg.Type {
+			messages = append(messages, msg.Type)
+		}
+
+		switch msg.Type {
+		case MetadataMessage:
+			if msg.Metadata.Title != "test" || len(msg.Metadata.Markers) != 1 {
+				t.Fatalf("Unexpected metadata: %+v", msg.Metadata)
+			}
+		case PositionMessage:
+			position = msg.Position
+		case DataMessage:
+			output.WriteString(msg.Data)
+
+			if msg.Data == "world" {
+				wsjson.Write(ctx, conn, Message{Type: SpeedMessage, Speed: 100})
+				wsjson.Write(ctx, conn, Message{Type: SeekMessage, Position: 50})
+			}
+		}
+	}
+
+	if messages[0] != MetadataMessage || position < 50 {
+		t.Fatalf("Unexpected messages %v, last position %f", messages, position)
+	}
+
+	if output.String() != "hello world!" {
+		t.Fatalf("Unexpected output: %q", output.String())
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	url := newTestServer(t, &Handler{Options: []Option{WithHandshakeTimeout(time.Second)}})
+
+	t.Run("not found", func(t *testing.T) {
+		resp, err := http.Get("http" + strings.TrimPrefix(strings.Replace(url, "test.cast", "other.cast", 1), "ws"))
+		if err != nil {
+			t.Fatalf("Request failed: %s", err)
+		}
+
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Unexpected status %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("small terminal", func(t *testing.T) {
+		conn := dial(t, url, Dimensions{Width: 10, Height: 5})
+
+		err := readOutput(t, conn, func(string) {})
+		if status := websocket.CloseStatus(err); status != websocket.StatusInternalError || !strings.Contains(err.Error(), player.ErrSmallTerminal.Error()) {
+			t.Fatalf("Unexpected close: %s", err)
+		}
+	})
+
+	t.Run("handshake", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, _, err := websocket.Dial(ctx, url, nil)
+		if err != nil {
+			t.Fatalf("Dial failed: %s", err)
+		}
+
+		defer conn.Close(websocket.StatusNormalClosure, "")
+
+		wsjson.Write(ctx, conn, Message{Type: PlayPauseMessage})
+
+		err = readOutput(t, conn, func(string) {})
+		if status := websocket.CloseStatus(err); status != websocket.StatusProtocolError {
+			t.Fatalf("Unexpected close: %s", err)
+		}
+	})
+}
+
+func TestCloseReason(t *testing.T) {
+	for _, tc := range []struct {
+		reason, expected string
+	}{
+		{"", ""},
+		{"short", "short"},
+		{strings.Repeat("x", 200), strings.Repeat("x", maxCloseReason)},
+		{strings.Repeat("x", maxCloseReason-1) + "é", strings.Repeat("x", maxCloseReason-1)},
+	} {
+		if actual := closeReason(tc.reason); actual != tc.expected {
+			t.Fatalf("Unexpected reason for %q: %q", tc.reason, actual)
+		}
+	}
+}
+
+func TestTerm_Context(t *testing.T) {
+	terms := make(chan *Term, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{Subprotocol}})
+		if err != nil {
+			return
+		}
+
+		term, err := New(ctx, conn)
+		if err != nil {
+			t.Errorf("New failed: %s", err)
+			return
+		}
+
+		terms <- term
+	}))
+	defer srv.Close()
+
+	dial(t, "ws"+strings.TrimPrefix(srv.URL, "http"), Dimensions{Width: 80, Height: 24})
+
+	term := <-terms
+	if w, h := term.Dimensions(); w != 80 || h != 24 {
+		t.Fatalf("Unexpected dimensions %dx%d", w, h)
+	}
+
+	if _, err := term.Write([]byte("ok")); err != nil {
+		t.Fatalf("Write failed: %s", err)
+	}
+
+	cancel()
+
+	if _, err := term.Write([]byte("fail")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Write after cancel must fail, got %v", err)
+	}
+}